@@ -0,0 +1,103 @@
+// Package audit records the commands changing swarms in an append-only log.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	// redacted replaces the values of flags holding secrets.
+	redacted = "<redacted>"
+)
+
+var (
+	// secretFlagPatterns match the names of flags holding secrets.
+	secretFlagPatterns = []string{"secret", "password", "token", "credential", "access-key", "external-id", "discovery-url"}
+)
+
+// Entry records a command changing a swarm.
+type Entry struct {
+	Time    time.Time         `json:"time"`               // Time is when the command started.
+	User    string            `json:"user"`               // User is the user running kocho.
+	Host    string            `json:"host"`               // Host is the host kocho ran on.
+	Command string            `json:"command"`            // Command is the kocho command, e.g. create.
+	Swarm   string            `json:"swarm"`              // Swarm is the name of the swarm the command changed.
+	StackId string            `json:"stack_id,omitempty"` // StackId is the ID of the stack of the swarm, if known.
+	Flags   map[string]string `json:"flags,omitempty"`    // Flags are the flags given to the command, with secrets redacted.
+	Result  string            `json:"result"`             // Result is either succeeded or failed.
+	Error   string            `json:"error,omitempty"`    // Error is the error message of a failed command.
+}
+
+// Log is an append-only log of entries.
+type Log interface {
+	// Append adds the entry to the end of the log.
+	Append(entry Entry) error
+
+	// Entries returns all entries of the log, oldest first.
+	Entries() ([]Entry, error)
+}
+
+// RedactFlags returns a copy of the flags, with the values of flags holding
+// secrets replaced.
+func RedactFlags(flags map[string]string) map[string]string {
+	result := map[string]string{}
+	for name, value := range flags {
+		if isSecretFlag(name) {
+			value = redacted
+		}
+		result[name] = value
+	}
+	return result
+}
+
+func isSecretFlag(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range secretFlagPatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the entries of the named swarm.
+func Filter(entries []Entry, swarmName string) []Entry {
+	var result []Entry
+	for _, entry := range entries {
+		if entry.Swarm == swarmName {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// encodeEntry returns the entry as a single JSON line.
+func encodeEntry(entry Entry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return append(data, '\n'), nil
+}
+
+// decodeEntries returns the entries of the JSON lines in data. Empty lines are skipped.
+func decodeEntries(data []byte) ([]Entry, error) {
+	var entries []Entry
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, errgo.Notef(err, "invalid entry in line %d", i+1)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
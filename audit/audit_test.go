@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactFlags(t *testing.T) {
+	flags := map[string]string{
+		"cluster-size":       "5",
+		"aws-keypair":        "ops",
+		"etcd-discovery-url": "https://discovery.etcd.io/abcdef",
+		"slack-token":        "xoxb-123",
+		"smtp-password":      "hunter2",
+		"aws-external-id":    "8f3c2a",
+	}
+
+	expected := map[string]string{
+		"cluster-size":       "5",
+		"aws-keypair":        "ops",
+		"etcd-discovery-url": redacted,
+		"slack-token":        redacted,
+		"smtp-password":      redacted,
+		"aws-external-id":    redacted,
+	}
+	if result := RedactFlags(flags); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+	if flags["slack-token"] != "xoxb-123" {
+		t.Fatalf("expected flags to be left unmodified")
+	}
+}
+
+func TestDecodeEntries(t *testing.T) {
+	data := []byte(`{"command":"create","swarm":"a","result":"succeeded"}
+
+{"command":"destroy","swarm":"b","result":"failed","error":"boom"}
+`)
+
+	entries, err := decodeEntries(data)
+	if err != nil {
+		t.Fatalf("Failed to decode entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Command != "create" || entries[1].Error != "boom" {
+		t.Fatalf("unexpected entries: %#v", entries)
+	}
+
+	if filtered := Filter(entries, "b"); len(filtered) != 1 || filtered[0].Command != "destroy" {
+		t.Fatalf("expected only the entry of swarm b, got %#v", filtered)
+	}
+
+	if _, err := decodeEntries([]byte("{\"command\":\"create\"}\nnot json\n")); err == nil {
+		t.Fatalf("expected error for invalid entry")
+	}
+}
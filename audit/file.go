@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errgo"
+)
+
+// NewFileLog returns a FileLog writing to the file at the given path.
+func NewFileLog(path string) *FileLog {
+	return &FileLog{path: path}
+}
+
+// FileLog is a Log kept in a local file, one JSON entry per line.
+type FileLog struct {
+	path string
+}
+
+// Append adds the entry to the end of the file, creating it if necessary.
+func (l *FileLog) Append(entry Entry) error {
+	data, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return errgo.Mask(err)
+	}
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+
+	// A single write keeps entries of concurrent kocho invocations intact
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return errgo.Mask(err)
+	}
+	return errgo.Mask(file.Close())
+}
+
+// Entries returns all entries of the file. A missing file has no entries.
+func (l *FileLog) Entries() ([]Entry, error) {
+	data, err := ioutil.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return decodeEntries(data)
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kocho-audit")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := NewFileLog(filepath.Join(dir, "kocho", "audit.log"))
+
+	entries, err := log.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected a missing log to have no entries, got %v %v", entries, err)
+	}
+
+	for _, command := range []string{"create", "kill-instance", "destroy"} {
+		if err := log.Append(Entry{Command: command, Swarm: "my-swarm", Result: "succeeded"}); err != nil {
+			t.Fatalf("Failed to append %s: %v", command, err)
+		}
+	}
+
+	entries, err = log.Entries()
+	if err != nil {
+		t.Fatalf("Failed to read entries: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Command != "create" || entries[2].Command != "destroy" {
+		t.Fatalf("expected entries in order of appending, got %#v", entries)
+	}
+}
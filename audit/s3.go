@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"strings"
+
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/provider"
+)
+
+const s3URLPrefix = "s3://"
+
+// ObjectStore stores objects in buckets, e.g. on S3.
+type ObjectStore interface {
+	// GetObject returns the data of the object, or provider.ErrNotFound if
+	// there is no such object.
+	GetObject(bucket, key string) ([]byte, error)
+	PutObject(bucket, key string, data []byte) (string, error)
+}
+
+// ParseS3URL returns the bucket and key of a URL like s3://bucket/key. It
+// returns false if the URL is no S3 URL.
+func ParseS3URL(url string) (string, string, bool) {
+	if !strings.HasPrefix(url, s3URLPrefix) {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(url, s3URLPrefix), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// NewS3Log returns an S3Log kept in the object with the given key in the bucket.
+func NewS3Log(store ObjectStore, bucket, key string) *S3Log {
+	return &S3Log{
+		store:  store,
+		bucket: bucket,
+		key:    key,
+	}
+}
+
+// S3Log is a Log kept in an S3 object, one JSON entry per line. Objects cannot
+// be appended to, so entries are added by rewriting the object. Entries added
+// concurrently by different invocations of kocho can get lost.
+type S3Log struct {
+	store  ObjectStore
+	bucket string
+	key    string
+}
+
+// Append adds the entry to the end of the object, creating it if necessary.
+func (l *S3Log) Append(entry Entry) error {
+	line, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	data, err := l.store.GetObject(l.bucket, l.key)
+	if err != nil && err != provider.ErrNotFound {
+		return errgo.Mask(err)
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+
+	if _, err := l.store.PutObject(l.bucket, l.key, append(data, line...)); err != nil {
+		return errgo.Mask(err)
+	}
+	return nil
+}
+
+// Entries returns all entries of the object. A missing object has no entries.
+func (l *S3Log) Entries() ([]Entry, error) {
+	data, err := l.store.GetObject(l.bucket, l.key)
+	if err == provider.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return decodeEntries(data)
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/giantswarm/kocho/provider"
+)
+
+type fakeObjectStore map[string][]byte
+
+func (s fakeObjectStore) GetObject(bucket, key string) ([]byte, error) {
+	data, ok := s[bucket+"/"+key]
+	if !ok {
+		return nil, provider.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s fakeObjectStore) PutObject(bucket, key string, data []byte) (string, error) {
+	s[bucket+"/"+key] = data
+	return "https://" + bucket + ".s3.amazonaws.com/" + key, nil
+}
+
+func TestS3Log(t *testing.T) {
+	store := fakeObjectStore{}
+	log := NewS3Log(store, "audit", "kocho/audit.log")
+
+	for _, swarm := range []string{"a", "b"} {
+		if err := log.Append(Entry{Command: "create", Swarm: swarm, Result: "succeeded"}); err != nil {
+			t.Fatalf("Failed to append: %v", err)
+		}
+	}
+
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("Failed to read entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Swarm != "a" || entries[1].Swarm != "b" {
+		t.Fatalf("expected entries in order of appending, got %#v", entries)
+	}
+}
+
+func TestParseS3URL(t *testing.T) {
+	tests := []struct {
+		url    string
+		bucket string
+		key    string
+		ok     bool
+	}{
+		{"s3://audit/kocho/audit.log", "audit", "kocho/audit.log", true},
+		{"s3://audit", "", "", false},
+		{"s3:///audit.log", "", "", false},
+		{"/var/log/kocho/audit.log", "", "", false},
+	}
+
+	for _, test := range tests {
+		bucket, key, ok := ParseS3URL(test.url)
+		if bucket != test.bucket || key != test.key || ok != test.ok {
+			t.Errorf("ParseS3URL(%s): expected %s %s %v, got %s %s %v", test.url, test.bucket, test.key, test.ok, bucket, key, ok)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/giantswarm/kocho/audit"
+)
+
+// recordAuditEntry appends the entry of the event to the audit log. Failing to
+// do so is reported, but doesn't fail the command.
+func recordAuditEntry(e *commandEvent) {
+	log := viperConfig.getAuditLog()
+	if log == nil {
+		return
+	}
+
+	host, _ := os.Hostname()
+	entry := audit.Entry{
+		Time:    e.Time,
+		User:    e.User,
+		Host:    host,
+		Command: e.Command,
+		Swarm:   e.Swarm,
+		StackId: e.stackId,
+		Flags:   audit.RedactFlags(givenFlags()),
+		Result:  e.Result(),
+		Error:   e.Error,
+	}
+	if err := log.Append(entry); err != nil {
+		exitError("failed to record command in audit log:", err)
+	}
+}
+
+// givenFlags returns the global and command flags explicitly given on the command line.
+func givenFlags() map[string]string {
+	flags := map[string]string{}
+	visit := func(f *pflag.Flag) {
+		flags[f.Name] = f.Value.String()
+	}
+
+	globalFlagset.Visit(visit)
+	if activeCommand != nil {
+		activeCommand.Flags.Visit(visit)
+	}
+	return flags
+}
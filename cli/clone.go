@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/juju/errgo"
+	"github.com/spf13/pflag"
+
+	"github.com/giantswarm/kocho/provider"
+	"github.com/giantswarm/kocho/swarm"
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+var (
+	cmdClone = &Command{
+		Name:        "clone",
+		Usage:       "[create flags] <existing swarm> <new swarm>",
+		Description: "Create a new swarm with the spec of an existing one. Create flags given on the command line override the recorded spec, the config file is ignored. Primary and standalone swarms get a fresh etcd discovery url",
+		Summary:     "Clone an existing swarm",
+		Run:         runClone,
+	}
+
+	cloneShowCreateFlags bool
+)
+
+// createFlagOverrides copies the value of a create flag from one CreateFlags to another.
+var createFlagOverrides = map[string]func(dst, src *swarmtypes.CreateFlags){
+	"type":                 func(dst, src *swarmtypes.CreateFlags) { dst.Type = src.Type },
+	"tags":                 func(dst, src *swarmtypes.CreateFlags) { dst.Tags = src.Tags },
+	"cluster-size":         func(dst, src *swarmtypes.CreateFlags) { dst.ClusterSize = src.ClusterSize },
+	"etcd-peers":           func(dst, src *swarmtypes.CreateFlags) { dst.EtcdPeers = src.EtcdPeers },
+	"etcd-discovery-url":   func(dst, src *swarmtypes.CreateFlags) { dst.EtcdDiscoveryURL = src.EtcdDiscoveryURL },
+	"attach-to":            func(dst, src *swarmtypes.CreateFlags) { dst.AttachTo = src.AttachTo },
+	"template-dir":         func(dst, src *swarmtypes.CreateFlags) { dst.TemplateDir = src.TemplateDir },
+	"image":                func(dst, src *swarmtypes.CreateFlags) { dst.ImageURI = src.ImageURI },
+	"certificate":          func(dst, src *swarmtypes.CreateFlags) { dst.CertificateURI = src.CertificateURI },
+	"machine-type":         func(dst, src *swarmtypes.CreateFlags) { dst.MachineType = src.MachineType },
+	"machine-types":        func(dst, src *swarmtypes.CreateFlags) { dst.MachineTypes = src.MachineTypes },
+	"spot-max-price":       func(dst, src *swarmtypes.CreateFlags) { dst.SpotMaxPrice = src.SpotMaxPrice },
+	"yochu":                func(dst, src *swarmtypes.CreateFlags) { dst.YochuVersion = src.YochuVersion },
+	"yochu-docker-version": func(dst, src *swarmtypes.CreateFlags) { dst.DockerVersion = src.DockerVersion },
+	"yochu-fleet-version":  func(dst, src *swarmtypes.CreateFlags) { dst.FleetVersion = src.FleetVersion },
+	"yochu-etcd-version":   func(dst, src *swarmtypes.CreateFlags) { dst.EtcdVersion = src.EtcdVersion },
+	"yochu-k8s-version":    func(dst, src *swarmtypes.CreateFlags) { dst.K8sVersion = src.K8sVersion },
+	"yochu-rkt-version":    func(dst, src *swarmtypes.CreateFlags) { dst.RktVersion = src.RktVersion },
+	"use-ignition":         func(dst, src *swarmtypes.CreateFlags) { dst.UseIgnition = src.UseIgnition },
+	"ttl":                  func(dst, src *swarmtypes.CreateFlags) { dst.TTL = src.TTL },
+	"aws-keypair":          func(dst, src *swarmtypes.CreateFlags) { dst.KeypairName = src.KeypairName },
+	"aws-vpc":              func(dst, src *swarmtypes.CreateFlags) { dst.VPC = src.VPC },
+	"aws-vpc-cidr":         func(dst, src *swarmtypes.CreateFlags) { dst.VPCCIDR = src.VPCCIDR },
+	"aws-subnet":           func(dst, src *swarmtypes.CreateFlags) { dst.Subnet = src.Subnet },
+	"aws-az":               func(dst, src *swarmtypes.CreateFlags) { dst.AvailabilityZone = src.AvailabilityZone },
+}
+
+func init() {
+	registerCreateFlags(&cmdClone.Flags)
+
+	cmdClone.Flags.BoolVar(&cloneShowCreateFlags, "show-flags", false, "Prints the used parameters and quits.")
+}
+
+func runClone(args []string) (exit int) {
+	if len(args) < 2 {
+		return exitError("no Swarm given. Usage: kocho clone <existing swarm> <new swarm>")
+	} else if len(args) > 2 {
+		return exitError("too many arguments. Usage: kocho clone <existing swarm> <new swarm>")
+	}
+	existingName, name := args[0], args[1]
+
+	if cloneShowCreateFlags {
+		_, flags, err := cloneFlags(existingName)
+		if err != nil {
+			return exitError(err)
+		}
+		data, err := json.MarshalIndent(flags, "", "  ")
+		if err != nil {
+			return exitError("Failed to json encode flags: %v", err)
+		}
+		fmt.Printf("%s\n", string(data))
+		return 0
+	}
+
+	event := startEvent("clone", name)
+	defer func() { event.fire(exit) }()
+
+	existing, flags, err := cloneFlags(existingName)
+	if err != nil {
+		return event.exitError(err)
+	}
+
+	// The discovery url isn't recorded in the spec, so secondaries not attached
+	// to a primary reuse the one of the existing swarm
+	if flags.Type == "secondary" && flags.AttachTo == "" && flags.EtcdDiscoveryURL == "" {
+		if flags.EtcdDiscoveryURL, err = existing.GetEtcdDiscoveryURL(); err != nil {
+			return event.exitError(fmt.Sprintf("couldn't get etcd discovery url of swarm: %s", existingName), err)
+		}
+	}
+
+	return createSwarm(event, name, flags)
+}
+
+// cloneFlags returns the existing swarm and the create flags of its spec, with
+// the create flags given on the command line applied.
+func cloneFlags(existingName string) (*swarm.Swarm, swarmtypes.CreateFlags, error) {
+	existing, err := swarmService.Get(existingName, swarm.AWS)
+	if err != nil {
+		return nil, swarmtypes.CreateFlags{}, errgo.Notef(err, "couldn't find swarm: %s", existingName)
+	}
+
+	spec, err := existing.GetSpec()
+	if err == provider.ErrNotFound {
+		return nil, swarmtypes.CreateFlags{}, errgo.Newf("couldn't clone swarm: %s was created without recording its spec", existingName)
+	} else if err != nil {
+		return nil, swarmtypes.CreateFlags{}, errgo.Notef(err, "couldn't get spec of swarm: %s", existingName)
+	}
+
+	return existing, applyCreateFlagOverrides(spec.CreateFlags, viperConfig.newViperCreateFlags(), &cmdClone.Flags), nil
+}
+
+// applyCreateFlagOverrides returns a copy of flags, with the values of all
+// create flags explicitly set in flagset taken from overrides. The template
+// bucket is not part of the spec and is always taken from overrides.
+func applyCreateFlagOverrides(flags, overrides swarmtypes.CreateFlags, flagset *pflag.FlagSet) swarmtypes.CreateFlags {
+	// Don't modify the AWS flags of the given spec
+	if flags.AWSCreateFlags != nil {
+		awsFlags := *flags.AWSCreateFlags
+		flags.AWSCreateFlags = &awsFlags
+	} else {
+		flags.AWSCreateFlags = &swarmtypes.AWSCreateFlags{}
+	}
+	if overrides.AWSCreateFlags == nil {
+		overrides.AWSCreateFlags = &swarmtypes.AWSCreateFlags{}
+	}
+	flags.TemplateBucket = overrides.TemplateBucket
+	flags.TemplatePrefix = overrides.TemplatePrefix
+
+	flagset.Visit(func(f *pflag.Flag) {
+		if override, ok := createFlagOverrides[f.Name]; ok {
+			override(&flags, &overrides)
+		}
+	})
+	return flags
+}
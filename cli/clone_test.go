@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+func TestApplyCreateFlagOverrides(t *testing.T) {
+	config := NewConfig()
+	config.SetConfigType("yaml")
+	if err := config.ReadConfig(strings.NewReader("machine-type: x3.xlarge\naws-keypair: config\naws-template-bucket: templates\n")); err != nil {
+		t.Fatalf("Invalid config: %v", err)
+	}
+
+	f := pflag.NewFlagSet("test", pflag.ExitOnError)
+	registerCreateFlags(f)
+	config.configFromPFlags(f)
+	if err := f.Parse([]string{"--cluster-size=5", "--aws-subnet=subnet-2"}); err != nil {
+		t.Fatalf("Failed to parse args: %v", err)
+	}
+
+	spec := swarmtypes.CreateFlags{
+		Type:        "standalone",
+		ClusterSize: 3,
+		MachineType: "m3.large",
+		AWSCreateFlags: &swarmtypes.AWSCreateFlags{
+			KeypairName: "spec",
+			Subnet:      "subnet-1",
+		},
+	}
+
+	flags := applyCreateFlagOverrides(spec, config.newViperCreateFlags(), f)
+
+	if flags.ClusterSize != 5 || flags.Subnet != "subnet-2" {
+		t.Fatalf("expected flags given on the command line to override the spec, got %#v", flags)
+	}
+	if flags.Type != "standalone" || flags.MachineType != "m3.large" || flags.KeypairName != "spec" {
+		t.Fatalf("expected values of the config file and flag defaults to be ignored, got %#v %#v", flags, flags.AWSCreateFlags)
+	}
+	if flags.TemplateBucket != "templates" || flags.TemplatePrefix != "kocho/" {
+		t.Fatalf("expected template bucket to be taken from the configuration, got %#v", flags.AWSCreateFlags)
+	}
+	if spec.Subnet != "subnet-1" {
+		t.Fatalf("expected spec to be left unmodified, got subnet %s", spec.Subnet)
+	}
+}
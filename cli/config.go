@@ -2,14 +2,26 @@ package cli
 
 import (
 	"os"
+	"strings"
 
+	"github.com/juju/errgo"
+	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/giantswarm/kocho/audit"
 	"github.com/giantswarm/kocho/dns"
+	"github.com/giantswarm/kocho/lock"
+	"github.com/giantswarm/kocho/notification"
+	"github.com/giantswarm/kocho/provider/aws/sdk"
 	"github.com/giantswarm/kocho/swarm/types"
 )
 
+const (
+	// dynamoDBLockPrefix prefixes the table to keep locks in, instead of a directory.
+	dynamoDBLockPrefix = "dynamodb:"
+)
+
 var (
 	// ConfigHomePath defines where to load configuration files from.
 	ConfigHomePath = os.Getenv("HOME") + "/.giantswarm/"
@@ -52,6 +64,7 @@ func (viper *KochoConfiguration) newViperCreateFlags() swarmtypes.CreateFlags {
 		Tags:             viper.GetString("tags"),
 		EtcdPeers:        viper.GetString("etcd-peers"),
 		EtcdDiscoveryURL: viper.GetString("etcd-discovery-url"),
+		AttachTo:         viper.GetString("attach-to"),
 		ClusterSize:      viper.GetInt("cluster-size"),
 
 		// Yochu Flags
@@ -63,11 +76,14 @@ func (viper *KochoConfiguration) newViperCreateFlags() swarmtypes.CreateFlags {
 		K8sVersion:    viper.GetString("yochu-k8s-version"),
 
 		// Provider interpreted
-		ImageURI:       viper.GetString("image"),
+		ImageURI:       viper.getImage(sdk.DefaultSessionProvider.ConfigRegion),
 		MachineType:    viper.GetString("machine-type"),
+		MachineTypes:   viper.GetString("machine-types"),
+		SpotMaxPrice:   viper.GetString("spot-max-price"),
 		CertificateURI: viper.GetString("certificate"),
 
 		UseIgnition: viper.GetBool("use-ignition"),
+		TTL:         viper.GetDuration("ttl"),
 
 		AWSCreateFlags: &swarmtypes.AWSCreateFlags{
 			KeypairName:      viper.GetString("aws-keypair"),
@@ -75,19 +91,97 @@ func (viper *KochoConfiguration) newViperCreateFlags() swarmtypes.CreateFlags {
 			VPC:              viper.GetString("aws-vpc"),
 			VPCCIDR:          viper.GetString("aws-vpc-cidr"),
 			AvailabilityZone: viper.GetString("aws-az"),
+			TemplateBucket:   viper.GetString("aws-template-bucket"),
+			TemplatePrefix:   viper.GetString("aws-template-prefix"),
 		},
 	}
 }
 
+// getRegions returns the regions given as comma separated list.
+func (viper *KochoConfiguration) getRegions() []string {
+	var regions []string
+	for _, region := range strings.Split(viper.GetString("regions"), ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// getImage returns the image to create swarms with in the given region. An
+// explicitly given image wins over the per region images mapping of the config.
+func (viper *KochoConfiguration) getImage(region string) string {
+	if image := viper.GetString("image"); image != "" {
+		return image
+	}
+
+	if image, ok := viper.GetStringMapString("images")[region]; ok {
+		return image
+	}
+	return defaultImage
+}
+
+// getNotificationConfig returns the notification receivers of the config file.
+func (viper *KochoConfiguration) getNotificationConfig() (notification.Configuration, error) {
+	var config notification.Configuration
+	if err := viper.UnmarshalKey("notifications", &config); err != nil {
+		return config, errgo.WithCausef(err, notification.ErrInvalidConfiguration, "couldn't decode notifications configuration")
+	}
+	return config, nil
+}
+
+// getAuditLog returns the log to record commands changing swarms in, or nil if
+// it is disabled.
+func (viper *KochoConfiguration) getAuditLog() audit.Log {
+	location := viper.GetString("audit-log")
+	if location == "" {
+		return nil
+	}
+
+	if bucket, key, ok := audit.ParseS3URL(location); ok {
+		return audit.NewS3Log(sdk.NewS3(""), bucket, key)
+	}
+	if expanded, err := homedir.Expand(location); err == nil {
+		location = expanded
+	}
+	return audit.NewFileLog(location)
+}
+
+// getLocker returns the locker of swarms being changed, or nil if locking is disabled.
+func (viper *KochoConfiguration) getLocker() *lock.Locker {
+	location := viper.GetString("lock")
+	if location == "" {
+		return nil
+	}
+
+	var backend lock.Backend
+	if strings.HasPrefix(location, dynamoDBLockPrefix) {
+		backend = lock.NewDynamoDBBackend(sdk.NewDynamoDB(""), strings.TrimPrefix(location, dynamoDBLockPrefix))
+	} else {
+		if expanded, err := homedir.Expand(location); err == nil {
+			location = expanded
+		}
+		backend = lock.NewFileBackend(location)
+	}
+
+	host, _ := os.Hostname()
+	return lock.NewLocker(backend, viper.GetDuration("lock-lease"), currentUser(), host)
+}
+
 func (viper *KochoConfiguration) getDNSServiceName() string {
 	return viper.GetString("dns-service")
 }
 
-func (viper *KochoConfiguration) getCloudflareConfig() dns.CloudFlareConfig {
-	return dns.CloudFlareConfig{
-		Email: os.Getenv("CLOUDFLARE_EMAIL"),
-		Token: os.Getenv("CLOUDFLARE_TOKEN"),
+// getCloudflareConfig returns the CloudFlare configuration of the config file,
+// with the credentials taken from the environment.
+func (viper *KochoConfiguration) getCloudflareConfig() (dns.CloudFlareConfig, error) {
+	var config dns.CloudFlareConfig
+	if err := viper.UnmarshalKey("cloudflare", &config); err != nil {
+		return config, errgo.Notef(err, "couldn't decode cloudflare configuration")
 	}
+	config.Email = os.Getenv("CLOUDFLARE_EMAIL")
+	config.Token = os.Getenv("CLOUDFLARE_TOKEN")
+	return config, nil
 }
 
 func (viper *KochoConfiguration) getDNSNamingPattern() dns.NamingPattern {
@@ -98,6 +192,7 @@ func (viper *KochoConfiguration) getDNSNamingPattern() dns.NamingPattern {
 		Public:          viper.GetString("dns-public"),
 		Private:         viper.GetString("dns-private"),
 		Fleet:           viper.GetString("dns-fleet"),
+		FleetRecords:    viper.GetString("dns-fleet-records"),
 	}
 }
 
@@ -107,7 +202,10 @@ func newDNSService(kocho *KochoConfiguration) dns.DNSService {
 	case "", "noop":
 		dnsService = dns.NewNoopDNS()
 	case "cloudflare":
-		config := kocho.getCloudflareConfig()
+		config, err := kocho.getCloudflareConfig()
+		if err != nil {
+			panic("Invalid cloudflare configuration: " + err.Error())
+		}
 		dnsService = dns.NewCloudFlareDNS(config)
 	default:
 		panic("Invalid dns-system: " + kocho.getDNSServiceName())
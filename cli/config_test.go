@@ -21,6 +21,10 @@ func machineType(obj *swarmtypes.CreateFlags) string {
 	return obj.MachineType
 }
 
+func imageURI(obj *swarmtypes.CreateFlags) string {
+	return obj.ImageURI
+}
+
 func awsKeypairName(obj *swarmtypes.CreateFlags) string {
 	return obj.AWSCreateFlags.KeypairName
 }
@@ -42,6 +46,12 @@ var testData = []struct {
 	{machineType, "machine-type: x3.xlarge", nil, "x3.xlarge", "machine-type: config file is used, when no flag is given"},
 	{machineType, "machine-type: x3.xlarge", []string{"--machine-type=t2.micro"}, "t2.micro", "machine-type: CLI wins over config file"},
 
+	{imageURI, "", nil, "coreos:stable", "image: defaults to the latest CoreOS stable image"},
+	{imageURI, "images:\n  eu-west-1: ami-12345678", nil, "ami-12345678", "image: per region mapping of the config file is used"},
+	{imageURI, "images:\n  us-east-1: ami-12345678", nil, "coreos:stable", "image: mapping of other regions is ignored"},
+	{imageURI, "images:\n  eu-west-1: ami-12345678", []string{"--image=ami-87654321"}, "ami-87654321", "image: CLI wins over per region mapping"},
+	{imageURI, "image: coreos:beta:1010.3.0", nil, "coreos:beta:1010.3.0", "image: CoreOS channel and version can be configured"},
+
 	{awsKeypairName, "aws-keypair: aws-config", nil, "aws-config", "keypair is read from config"},
 	{awsKeypairName, "aws-keypair: aws-config", []string{"--aws-keypair=cli"}, "cli", "Keypair is configurable via CLI"},
 }
@@ -9,10 +9,12 @@ import (
 	"github.com/giantswarm/kocho/dns"
 	"github.com/giantswarm/kocho/provider"
 	"github.com/giantswarm/kocho/swarm"
+	"github.com/giantswarm/kocho/swarm/types"
 )
 
-// CoreOS Stable 681.2.0 (HVM eu-west-1)
-const awsEuWest1CoreOS = "ami-5f2f5528"
+// defaultImage is used if neither --image nor the images mapping of the config
+// provide one. It is resolved to the latest CoreOS stable image of the region.
+const defaultImage = "coreos:stable"
 
 var (
 	cmdCreate = &Command{
@@ -38,11 +40,14 @@ func registerCreateFlags(flagset *pflag.FlagSet) {
 	flagset.Int("cluster-size", 3, "number of nodes a cluster should have")
 	flagset.String("etcd-peers", "", "etcd peers a secondary swarm is connecting to")
 	flagset.String("etcd-discovery-url", "", "etcd discovery url for a secondary swarm is connecting to")
+	flagset.String("attach-to", "", "primary swarm to attach a secondary swarm to - resolves --etcd-peers and --etcd-discovery-url from the primary swarm")
 	flagset.String("template-dir", "templates", "directory to use for reading templates (see template-init command)")
 
-	flagset.String("image", awsEuWest1CoreOS, "image version that should be used to create a swarm")
+	flagset.String("image", "", "image that should be used to create a swarm, either an AMI ID or coreos:<stable|beta|alpha>[:<version>] - defaults to the images mapping of the config for the region, or coreos:stable")
 	flagset.String("certificate", "", "certificate ARN to use to create aws cluster")
 	flagset.String("machine-type", "m3.large", "machine type to use, e.g. m3.large for AWS")
+	flagset.String("machine-types", "", "comma separated list of machine types to mix in secondary and standalone swarms, e.g. m4.large,m5.large")
+	flagset.String("spot-max-price", "", "maximum hourly price for spot instances in secondary and standalone swarms - on-demand instances are used if empty")
 
 	// Yochu
 	flagset.String("yochu", "", "version of Yochu to provision cluster nodes")
@@ -53,13 +58,16 @@ func registerCreateFlags(flagset *pflag.FlagSet) {
 	flagset.String("yochu-rkt-version", "v1.1.0", "version to use when provisioning rkt binaries")
 
 	flagset.Bool("use-ignition", false, "use ignition configuration templates")
+	flagset.Duration("ttl", 0, "lifetime of the swarm, e.g. 48h - expired swarms are destroyed by 'kocho reap'. Zero means the swarm never expires")
 
 	// AWS Provider specific
 	flagset.String("aws-keypair", "", "Keypair to use for AWS machines")
 	flagset.String("aws-vpc", "", "VPC to use for new AWS machines")
 	flagset.String("aws-vpc-cidr", "", "VPC CIDR to use for security configuration")
-	flagset.String("aws-subnet", "", "Subnet to use for new AWS machines")
-	flagset.String("aws-az", "", "AZ to use for new AWS machines")
+	flagset.String("aws-subnet", "", "comma separated list of subnets to spread new AWS machines across")
+	flagset.String("aws-az", "", "comma separated list of AZs the subnets are allowed to be in (defaults to the AZs of the subnets)")
+	flagset.String("aws-template-bucket", "", "S3 bucket to upload CloudFormation templates to that are too large to be passed inline")
+	flagset.String("aws-template-prefix", "kocho/", "key prefix for CloudFormation templates uploaded to the template bucket")
 }
 
 func runCreate(args []string) (exit int) {
@@ -74,51 +82,70 @@ func runCreate(args []string) (exit int) {
 		return
 	}
 
+	if len(args) == 0 {
+		return exitError("no Swarm given. Usage: kocho create <swarm>")
+	} else if len(args) > 1 {
+		return exitError("too many arguments. Usage: kocho create <swarm>")
+	}
+	name := args[0]
+
+	event := startEvent("create", name)
+	defer func() { event.fire(exit) }()
+
+	return createSwarm(event, name, flags)
+}
+
+// createSwarm creates a swarm with the given flags and, unless --no-block is
+// given, waits for it and creates its DNS entries. The outcome is recorded in
+// the given event.
+func createSwarm(event *commandEvent, name string, flags swarmtypes.CreateFlags) (exit int) {
+	event.SwarmType = flags.Type
+
 	if flags.FleetVersion == "" {
-		return exitError("couldn't create swarm: fleet version must be set using --fleet-version=<version>")
+		return event.exitError("couldn't create swarm: fleet version must be set using --fleet-version=<version>")
 	}
 
 	if flags.EtcdVersion == "" {
-		return exitError("couldn't create swarm: etcd version must be set using --etcd-version=<version>")
+		return event.exitError("couldn't create swarm: etcd version must be set using --etcd-version=<version>")
 	}
 
 	if flags.MachineType == "" {
-		return exitError("couldn't create swarm: --machine-type must be provided")
+		return event.exitError("couldn't create swarm: --machine-type must be provided")
 	}
 	if flags.ImageURI == "" {
-		return exitError("couldn't create swarm: --image must be provided")
+		return event.exitError("couldn't create swarm: --image must be provided")
 	}
 
-	if flags.UseIgnition && flags.ImageURI == awsEuWest1CoreOS {
-		return exitError(fmt.Sprintf("couldn't create swarm: --use-ignition requires a more recent CoreOS AMI than '%s'", awsEuWest1CoreOS))
+	release, err := lockSwarm(event)
+	if err != nil {
+		return event.exitError("couldn't lock swarm:", err)
 	}
+	defer release()
 
-	if len(args) == 0 {
-		return exitError("no Swarm given. Usage: kocho create <swarm>")
-	} else if len(args) > 1 {
-		return exitError("too many arguments. Usage: kocho create <swarm>")
-	}
-	name := args[0]
+	event.notifyStart()
 
 	s, err := swarmService.Create(name, swarm.AWS, flags)
 	if err != nil {
-		return exitError(fmt.Sprintf("couldn't create swarm: %s", name), err)
+		return event.exitError(fmt.Sprintf("couldn't create swarm: %s", name), err)
 	}
+	event.setSwarm(s)
 
 	if !sharedFlags.NoBlock {
+		event.notifyProgress("Triggered creation of swarm %s, waiting for it to start", name)
 		err = s.WaitUntil(provider.StatusCreated)
 		if err != nil {
-			return exitError("couldn't find out if swarm was started correctly", err)
+			return event.exitError("couldn't find out if swarm was started correctly", err)
 		}
+		event.notifyProgress("Swarm %s started, creating DNS entries", name)
 
-		err = dns.CreateSwarmEntries(dnsService, viperConfig.getDNSNamingPattern(), s)
+		changes, err := dns.CreateSwarmEntries(dnsService, viperConfig.getDNSNamingPattern(), s)
 		if err != nil {
-			return exitError("couldn't create dns entries", err)
+			return event.exitError("couldn't create dns entries", err)
 		}
+		event.DNSEntries = dns.Names(changes)
 	} else {
 		fmt.Printf("triggered swarm %s start. No DNS will be configured\n", name)
 	}
-	fireNotification()
 
 	return 0
 }
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/giantswarm/kocho/provider"
+	"github.com/giantswarm/kocho/swarm"
+	"github.com/giantswarm/kocho/swarm/types"
+	"github.com/ryanuber/columnize"
+)
+
+var cmdDescribe = &Command{
+	Name:        "describe",
+	Usage:       "<swarm>",
+	Description: "Show the spec a swarm was created with, its stack outputs, load balancers, DNS entries and instances",
+	Summary:     "Describe a swarm",
+	Run:         runDescribe,
+}
+
+func runDescribe(args []string) (exit int) {
+	if len(args) == 0 {
+		return exitError("no Swarm given. Usage: kocho describe <swarm>")
+	} else if len(args) > 1 {
+		return exitError("too many arguments. Usage: kocho describe <swarm>")
+	}
+	swarmName := args[0]
+
+	s, err := swarmService.Get(swarmName, swarm.AWS)
+	if err != nil {
+		return exitError(fmt.Sprintf("couldn't describe swarm: %s", swarmName), err)
+	}
+
+	status, reason, err := s.GetStatus()
+	if err != nil {
+		return exitError(fmt.Sprintf("couldn't describe swarm: %s", swarmName), err)
+	}
+
+	printSection("Swarm", []string{
+		"Name | " + s.Name,
+		"Type | " + orDash(s.Type),
+		"Region | " + s.Region,
+		"Created | " + s.Created.Format(time.RFC822),
+		"Status | " + formatStatus(status, reason),
+		"Protected | " + strconv.FormatBool(s.IsProtected()),
+	})
+
+	spec, err := s.GetSpec()
+	switch {
+	case err == provider.ErrNotFound:
+		printSection("Spec", []string{"- | not recorded, the swarm was created by an older version of kocho"})
+	case err != nil:
+		return exitError(fmt.Sprintf("couldn't get spec of swarm: %s", swarmName), err)
+	default:
+		printSection("Spec", specLines(*spec))
+	}
+
+	outputs, err := s.GetOutputs()
+	if err != nil {
+		return exitError(fmt.Sprintf("couldn't get outputs of swarm: %s", swarmName), err)
+	}
+	printSection("Outputs", mapLines(outputs))
+
+	// Primary swarms have no public load balancer, so lookup errors are shown as missing
+	publicDNS, _ := s.GetPublicDNS()
+	privateDNS, _ := s.GetPrivateDNS()
+	printSection("Load Balancers", []string{
+		"Public | " + orDash(publicDNS),
+		"Private | " + orDash(privateDNS),
+	})
+
+	entries := viperConfig.getDNSNamingPattern().GetEntries(s.Name)
+	printSection("DNS", []string{
+		"Public | " + entries.Public,
+		"Private | " + entries.Private,
+		"Catchall | " + entries.Catchall,
+		"CatchallPrivate | " + entries.CatchallPrivate,
+		"Fleet | " + entries.Fleet,
+	})
+
+	instances, err := s.GetInstances()
+	if err != nil {
+		return exitError(fmt.Sprintf("couldn't get instances of swarm: %s", swarmName), err)
+	}
+	fmt.Println("Instances:")
+	fmt.Println(columnize.SimpleFormat(formatInstances(instances)))
+	return 0
+}
+
+func printSection(title string, lines []string) {
+	fmt.Printf("%s:\n", title)
+	fmt.Println(columnize.SimpleFormat(lines))
+	fmt.Println()
+}
+
+func formatStatus(status, reason string) string {
+	if reason != "" {
+		return fmt.Sprintf("%s (%s)", status, reason)
+	}
+	return status
+}
+
+func specLines(spec swarmtypes.Spec) []string {
+	lines := []string{
+		"Type | " + orDash(spec.Type),
+		"Cluster size | " + strconv.Itoa(spec.ClusterSize),
+		"Image | " + orDash(spec.ImageURI),
+		"Machine type | " + orDash(spec.MachineType),
+		"Machine types | " + orDash(spec.MachineTypes),
+		"Spot max price | " + orDash(spec.SpotMaxPrice),
+		"Certificate | " + orDash(spec.CertificateURI),
+		"Tags | " + orDash(spec.Tags),
+		"Ignition | " + strconv.FormatBool(spec.UseIgnition),
+		"Yochu version | " + orDash(spec.YochuVersion),
+		"Docker version | " + orDash(spec.DockerVersion),
+		"Etcd version | " + orDash(spec.EtcdVersion),
+		"Fleet version | " + orDash(spec.FleetVersion),
+		"K8s version | " + orDash(spec.K8sVersion),
+		"Rkt version | " + orDash(spec.RktVersion),
+		"Attached to | " + orDash(spec.AttachTo),
+		"Etcd peers | " + orDash(spec.EtcdPeers),
+	}
+
+	if spec.AWSCreateFlags != nil {
+		lines = append(lines,
+			"Keypair | "+orDash(spec.KeypairName),
+			"VPC | "+orDash(spec.VPC),
+			"VPC CIDR | "+orDash(spec.VPCCIDR),
+			"Subnets | "+orDash(spec.Subnet),
+			"Availability zones | "+orDash(spec.AvailabilityZone),
+		)
+	}
+
+	return append(lines,
+		"Template dir | "+orDash(spec.TemplateDir),
+		"Template hash | "+orDash(spec.TemplateHash),
+		"Kocho version | "+orDash(spec.KochoVersion),
+		"Creator | "+orDash(spec.Creator),
+	)
+}
+
+// mapLines returns the entries of the map as columns, sorted by key.
+func mapLines(values map[string]string) []string {
+	if len(values) == 0 {
+		return []string{"-"}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+" | "+values[key])
+	}
+	return lines
+}
@@ -34,40 +34,79 @@ func runDestroy(args []string) (exit int) {
 	}
 	swarmName := args[0]
 
+	event := startEvent("destroy", swarmName)
+	defer func() { event.fire(exit) }()
+
+	release, err := lockSwarm(event)
+	if err != nil {
+		return event.exitError("couldn't lock swarm:", err)
+	}
+	defer release()
+
 	s, err := swarmService.Get(swarmName, swarm.AWS)
 	if err != nil {
-		return exitError(fmt.Sprintf("couldn't find swarm: %s", swarmName), err)
+		return event.exitError(fmt.Sprintf("couldn't find swarm: %s", swarmName), err)
 	}
+	event.setSwarm(s)
 
-	if !forceDestroying {
+	if s.IsProtected() {
+		return protectedError(event, "destroy swarm", swarmName)
+	}
+
+	var secondaries []*swarm.Swarm
+	if s.Type == "primary" {
+		secondaries, err = swarmService.GetSecondaries(s)
+		if err != nil {
+			return event.exitError(fmt.Sprintf("couldn't find secondary swarms of swarm: %s", swarmName), err)
+		}
+	}
+
+	// Destroying a primary swarm breaks its secondaries, so this is confirmed even with --force
+	if len(secondaries) > 0 {
+		fmt.Printf("swarm '%s' is the primary swarm of these secondary swarms:\n", swarmName)
+		for _, secondary := range secondaries {
+			fmt.Printf("  %s\n", secondary.Name)
+		}
+		if err := confirmInput(fmt.Sprintf("are you sure you want to destroy '%s'? Enter the name of the swarm:", swarmName), swarmName); err != nil {
+			return event.exitError("failed to read from stdin", err)
+		}
+	} else if !forceDestroying {
 		if err := confirm(fmt.Sprintf("are you sure you want to destroy '%s'? Enter yes:", swarmName)); err != nil {
-			return exitError("failed to read from stdin", err)
+			return event.exitError("failed to read from stdin", err)
 		}
 	}
 
+	event.notifyStart()
+
 	if err := s.Destroy(); err != nil {
-		return exitError(fmt.Sprintf("couldn't delete swarm: %s", swarmName), err)
+		return event.exitError(fmt.Sprintf("couldn't delete swarm: %s", swarmName), err)
 	}
 
-	err = dns.DeleteEntries(dnsService, viperConfig.getDNSNamingPattern(), swarmName)
+	changes, err := dns.DeleteEntries(dnsService, viperConfig.getDNSNamingPattern(), swarmName)
 	if err != nil {
-		return exitError("couldn't delete dns entries", err)
+		return event.exitError("couldn't delete dns entries", err)
 	}
+	event.DNSEntries = dns.Names(changes)
 
 	if !sharedFlags.NoBlock {
+		event.notifyProgress("Triggered deletion of swarm %s and deleted its DNS entries, waiting for it to be deleted", swarmName)
 		err := s.WaitUntil(provider.StatusDeleted)
 		if err != nil {
-			return exitError("couldn't find out if swarm was deleted correctly", err)
+			return event.exitError("couldn't find out if swarm was deleted correctly", err)
 		}
 	} else {
 		fmt.Printf("triggered swarm %s deletion\n", swarmName)
 	}
-	fireNotification()
 
 	return 0
 }
 
 func confirm(question string) error {
+	return confirmInput(question, "yes")
+}
+
+// confirmInput asks the question until the expected answer is entered.
+func confirmInput(question, expected string) error {
 	for {
 		fmt.Printf("%s ", question)
 		bio := bufio.NewReader(os.Stdin)
@@ -76,9 +115,9 @@ func confirm(question string) error {
 			return err
 		}
 
-		if string(line) == "yes" {
+		if string(line) == expected {
 			return nil
 		}
-		fmt.Println("please enter 'yes' to confirm")
+		fmt.Printf("please enter '%s' to confirm\n", expected)
 	}
 }
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errgo"
+	"github.com/ryanuber/columnize"
+
+	"github.com/giantswarm/kocho/provider"
+	"github.com/giantswarm/kocho/swarm"
+)
+
+var (
+	cmdDiff = &Command{
+		Name:        "diff",
+		Usage:       "[--drift] <swarm>",
+		Description: "Compare the template, parameters and cloud config of a running swarm with what its recorded spec and the current templates produce. With --drift, CloudFormation drift detection reports resources modified outside of kocho. Exits with 1 if differences are found",
+		Summary:     "Show differences between a swarm and its spec",
+		Run:         runDiff,
+	}
+
+	flagDiffDrift bool
+)
+
+const (
+	driftHeader = "Resource | Type | PhysicalId | Drift"
+	driftScheme = "%s | %s | %s | %s"
+)
+
+func init() {
+	cmdDiff.Flags.BoolVar(&flagDiffDrift, "drift", false, "also run drift detection on the resources of the swarm")
+}
+
+func runDiff(args []string) (exit int) {
+	if len(args) == 0 {
+		return exitError("no Swarm given. Usage: kocho diff <swarm>")
+	} else if len(args) > 1 {
+		return exitError("too many arguments. Usage: kocho diff <swarm>")
+	}
+	swarmName := args[0]
+
+	s, err := swarmService.Get(swarmName, swarm.AWS)
+	if err != nil {
+		return exitError(fmt.Sprintf("couldn't find swarm: %s", swarmName), err)
+	}
+
+	rendered, deployed, err := swarmService.GetStackDocuments(s)
+	if errgo.Cause(err) == provider.ErrNotFound {
+		return exitError(fmt.Sprintf("couldn't diff swarm: %s was created without recording its spec", swarmName))
+	} else if err != nil {
+		return exitError(fmt.Sprintf("couldn't render templates of swarm: %s", swarmName), err)
+	}
+
+	diffs := []string{
+		unifiedDiff("deployed/template", "rendered/template", deployed.Template, rendered.Template),
+		unifiedDiff("deployed/parameters", "rendered/parameters", formatParameters(deployed.Parameters), formatParameters(rendered.Parameters)),
+		unifiedDiff("deployed/config", "rendered/config", deployed.Config, rendered.Config),
+	}
+
+	changed := false
+	for _, d := range diffs {
+		if d != "" {
+			fmt.Print(d)
+			changed = true
+		}
+	}
+	if !changed {
+		fmt.Printf("swarm %s matches its spec\n", swarmName)
+	}
+
+	if flagDiffDrift {
+		drifts, err := s.DetectDrift()
+		if err != nil {
+			return exitError(fmt.Sprintf("couldn't detect drift of swarm: %s", swarmName), err)
+		}
+
+		if len(drifts) == 0 {
+			fmt.Printf("no resources of swarm %s have drifted\n", swarmName)
+		} else {
+			lines := []string{driftHeader}
+			for _, d := range drifts {
+				lines = append(lines, fmt.Sprintf(driftScheme, d.LogicalId, d.Type, orDash(d.PhysicalId), d.Status))
+			}
+			fmt.Println(columnize.SimpleFormat(lines))
+			changed = true
+		}
+	}
+
+	if changed {
+		return 1
+	}
+	return 0
+}
+
+// formatParameters returns the parameters as sorted key=value lines.
+func formatParameters(parameters map[string]string) string {
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var text string
+	for _, key := range keys {
+		text += fmt.Sprintf("%s=%s\n", key, parameters[key])
+	}
+	return text
+}
@@ -3,25 +3,35 @@ package cli
 import (
 	"fmt"
 
+	"github.com/juju/errgo"
+	"github.com/ryanuber/columnize"
+
 	"github.com/giantswarm/kocho/dns"
 	"github.com/giantswarm/kocho/swarm"
 )
 
 var (
-	flagDelete bool
-	cmdDns     = &Command{
+	flagDelete    bool
+	flagDnsDryRun bool
+	cmdDns        = &Command{
 		Name:        "dns",
 		Summary:     "Update DNS of a swarm",
-		Usage:       "[--delete] <swarm>",
-		Description: "Updating public, private and fleet dns entries of a swarm. With the --delete flag you can also just delete the current DNS entries",
+		Usage:       "[--delete] [--dry-run] <swarm>",
+		Description: "Updating public, private and fleet dns entries of a swarm, so they point to its current load balancers and instances. Only records that differ are changed. With the --delete flag you can also just delete the current DNS entries",
 		Run:         runDns,
 	}
 )
 
 func init() {
 	cmdDns.Flags.BoolVar(&flagDelete, "delete", false, "delete DNS entries of a swarm")
+	cmdDns.Flags.BoolVar(&flagDnsDryRun, "dry-run", false, "only show the planned changes of the DNS records")
 }
 
+const (
+	dnsChangesHeader = "Action | Entry | Name | Type | Content"
+	dnsChangesScheme = "%s | %s | %s | %s | %s"
+)
+
 func runDns(args []string) (exit int) {
 	if len(args) == 0 {
 		return exitError("no Swarm given. Usage: kocho dns <swarm>")
@@ -30,23 +40,77 @@ func runDns(args []string) (exit int) {
 	}
 	name := args[0]
 
-	err := dns.DeleteEntries(dnsService, viperConfig.getDNSNamingPattern(), name)
+	if flagDnsDryRun {
+		_, changes, err := planDns(name)
+		if err != nil {
+			return exitError("couldn't plan dns changes", err)
+		}
+		printDnsChanges(name, changes)
+		return 0
+	}
+
+	event := startEvent("dns", name)
+	defer func() { event.fire(exit) }()
+
+	release, err := lockSwarm(event)
 	if err != nil {
-		return exitError("couldn't delete dns entries", err)
+		return event.exitError("couldn't lock swarm:", err)
 	}
+	defer release()
 
-	if !flagDelete {
-		s, err := swarmService.Get(name, swarm.AWS)
-		if err != nil {
-			return exitError(fmt.Sprintf("couldn't find swarm: %s", name), err)
-		}
+	s, changes, err := planDns(name)
+	if err != nil {
+		return event.exitError("couldn't plan dns changes", err)
+	}
+	if s != nil {
+		event.setSwarm(s)
+	}
 
-		err = dns.CreateSwarmEntries(dnsService, viperConfig.getDNSNamingPattern(), s)
-		if err != nil {
-			return exitError("couldn't update dns entries", err)
-		}
+	if err := dns.Apply(dnsService, viperConfig.getDNSNamingPattern(), changes); err != nil {
+		return event.exitError("couldn't update dns entries", err)
 	}
-	fireNotification()
+	event.DNSEntries = dns.Names(changes)
 
+	printDnsChanges(name, changes)
 	return 0
 }
+
+// planDns returns the changes of the DNS records of the named swarm, and the
+// swarm unless its records are deleted.
+func planDns(name string) (*swarm.Swarm, []dns.Change, error) {
+	pattern := viperConfig.getDNSNamingPattern()
+	if flagDelete {
+		changes, err := dns.PlanDelete(dnsService, pattern, name)
+		return nil, changes, err
+	}
+
+	s, err := swarmService.Get(name, swarm.AWS)
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "couldn't find swarm: %s", name)
+	}
+	instances, err := s.GetInstances()
+	if err != nil {
+		return nil, nil, errgo.Notef(err, "couldn't get instances of swarm: %s", name)
+	}
+
+	changes, err := dns.PlanUpdate(dnsService, pattern, s, instances)
+	return s, changes, err
+}
+
+// printDnsChanges prints the changes of the DNS records of the named swarm.
+func printDnsChanges(name string, changes []dns.Change) {
+	if len(changes) == 0 {
+		fmt.Printf("dns entries of swarm %s are up to date\n", name)
+		return
+	}
+
+	lines := []string{dnsChangesHeader}
+	for _, change := range changes {
+		content := change.Record.Content
+		if change.Current != nil {
+			content = fmt.Sprintf("%s -> %s", change.Current.Content, content)
+		}
+		lines = append(lines, fmt.Sprintf(dnsChangesScheme, change.Action, change.Entry, change.Record.Name, change.Record.Type, content))
+	}
+	fmt.Println(columnize.SimpleFormat(lines))
+}
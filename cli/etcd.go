@@ -2,12 +2,8 @@ package cli
 
 import (
 	"fmt"
-	"strings"
 
-	"github.com/giantswarm/kocho/ssh"
 	"github.com/giantswarm/kocho/swarm"
-
-	"github.com/juju/errgo"
 )
 
 var cmdEtcd = &Command{
@@ -30,29 +26,19 @@ func runEtcd(args []string) (exit int) {
 		return exitError(fmt.Sprintf("couldn't get instances of swarm: %s", swarmName), err)
 	}
 
-	instances, err := s.GetInstances()
-	if err != nil {
-		exitError(err)
-	}
-
-	if len(instances) == 0 {
-		return exitError(errgo.Newf("could not find any running instances in swarm %s", swarmName))
-	}
-
 	switch subCommand {
 	case "discovery":
-		url, err := ssh.GetEtcdDiscoveryUrl(instances[0].PublicIPAddress)
+		url, err := s.GetEtcdDiscoveryURL()
 		if err != nil {
 			return exitError(err)
 		}
 
 		fmt.Println(url)
 	case "peers":
-		etcdPeers := []string{}
-		for _, instance := range instances {
-			etcdPeers = append(etcdPeers, fmt.Sprintf("http://%v:2379", instance.PrivateIPAddress))
+		peers, err := s.GetEtcdPeers()
+		if err != nil {
+			return exitError(err)
 		}
-		peers := strings.Join(etcdPeers, ",")
 
 		fmt.Println(peers)
 	}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ryanuber/columnize"
+
+	"github.com/giantswarm/kocho/audit"
+)
+
+var (
+	cmdHistory = &Command{
+		Name:        "history",
+		Usage:       "[--limit=<n>] [swarm]",
+		Description: "Show the commands changing swarms recorded in the audit log, optionally only those of the given swarm",
+		Summary:     "Show the history of commands changing swarms",
+		Run:         runHistory,
+	}
+
+	flagHistoryLimit int
+)
+
+func init() {
+	cmdHistory.Flags.IntVar(&flagHistoryLimit, "limit", 0, "only show the given number of most recent entries")
+}
+
+const (
+	historyHeader = "Time | User | Host | Command | Swarm | Result | Error"
+	historyScheme = "%s | %s | %s | %s | %s | %s | %s"
+)
+
+func runHistory(args []string) (exit int) {
+	if len(args) > 1 {
+		return exitError("too many arguments. Usage: kocho history [swarm]")
+	}
+
+	log := viperConfig.getAuditLog()
+	if log == nil {
+		return exitError("no audit log configured. Use --audit-log or set audit-log in kocho.yml")
+	}
+
+	entries, err := log.Entries()
+	if err != nil {
+		return exitError("couldn't read audit log", err)
+	}
+	if len(args) == 1 {
+		entries = audit.Filter(entries, args[0])
+	}
+	if flagHistoryLimit > 0 && len(entries) > flagHistoryLimit {
+		entries = entries[len(entries)-flagHistoryLimit:]
+	}
+
+	lines := []string{historyHeader}
+	for _, e := range entries {
+		lines = append(lines, fmt.Sprintf(historyScheme, e.Time.Local().Format(time.RFC822), e.User, orDash(e.Host), e.Command, e.Swarm, e.Result, orDash(e.Error)))
+	}
+	fmt.Println(columnize.SimpleFormat(lines))
+
+	return 0
+}
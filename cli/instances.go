@@ -2,23 +2,34 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/giantswarm/kocho/swarm"
+	"github.com/giantswarm/kocho/swarm/types"
 	"github.com/ryanuber/columnize"
 )
 
-var cmdInstances = &Command{
-	Name:        "instances",
-	Description: "List instances of a swarm",
-	Summary:     "List all the dns names of the instances of a swarm",
-	Run:         runInstances,
-}
+var (
+	cmdInstances = &Command{
+		Name:        "instances",
+		Usage:       "[--all] <swarm>",
+		Description: "List instances of a swarm. By default only running instances are shown, use --all to also list pending and terminating instances",
+		Summary:     "List all the dns names of the instances of a swarm",
+		Run:         runInstances,
+	}
+
+	flagInstancesAll bool
+)
 
 const (
-	instancesHeader = "Id | Image | Type | PublicDns | PrivateDns"
-	instancesScheme = "%s | %s | %s | %s | %s"
+	instancesHeader = "Id | Image | Type | Market | State | AZ | Launched | Health | Lifecycle | PublicDns | PrivateDns"
+	instancesScheme = "%s | %s | %s | %s | %s | %s | %s | %s | %s | %s | %s"
 )
 
+func init() {
+	cmdInstances.Flags.BoolVar(&flagInstancesAll, "all", false, "also show instances that are not running")
+}
+
 func runInstances(args []string) (exit int) {
 	if len(args) == 0 {
 		return exitError("no Swarm given. Usage: kocho instances <swarm>")
@@ -32,15 +43,58 @@ func runInstances(args []string) (exit int) {
 		return exitError(fmt.Sprintf("couldn't get instances of swarm: %s", swarmName), err)
 	}
 
-	instances, err := s.GetInstances()
+	var instances []swarmtypes.Instance
+	if flagInstancesAll {
+		instances, err = s.GetAllInstances()
+	} else {
+		instances, err = s.GetInstances()
+	}
 	if err != nil {
 		return exitError(fmt.Sprintf("couldn't get instances of swarm: %s", swarmName), err)
 	}
 
+	fmt.Println(columnize.SimpleFormat(formatInstances(instances)))
+	return 0
+}
+
+// formatInstances returns the header and a row per instance of the instances table.
+func formatInstances(instances []swarmtypes.Instance) []string {
 	lines := []string{instancesHeader}
 	for _, i := range instances {
-		lines = append(lines, fmt.Sprintf(instancesScheme, i.Id, i.Image, i.Type, i.PublicDNSName, i.PrivateDNSName))
+		lines = append(lines, fmt.Sprintf(instancesScheme,
+			i.Id, i.Image, i.Type, formatMarket(i), formatState(i), i.AvailabilityZone, formatLaunchTime(i.LaunchTime),
+			orDash(i.HealthStatus), orDash(i.LifecycleState), orDash(i.PublicDNSName), orDash(i.PrivateDNSName),
+		))
 	}
-	fmt.Println(columnize.SimpleFormat(lines))
-	return 0
+	return lines
+}
+
+// formatMarket highlights spot instances that are about to be, or have been, reclaimed.
+func formatMarket(i swarmtypes.Instance) string {
+	if i.SpotInterrupted() {
+		return fmt.Sprintf("%s (%s)", i.Market, i.SpotStatus)
+	}
+	return orDash(i.Market)
+}
+
+func formatState(i swarmtypes.Instance) string {
+	if i.StateReason != "" {
+		return fmt.Sprintf("%s (%s)", i.State, i.StateReason)
+	}
+	return i.State
+}
+
+func formatLaunchTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC822)
+}
+
+// orDash returns "-" for empty values, so columns stay aligned.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
 }
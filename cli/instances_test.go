@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+func TestFormatInstances(t *testing.T) {
+	lines := formatInstances([]swarmtypes.Instance{
+		{Id: "i-1", Image: "ami-1", Type: "m3.large", Market: swarmtypes.MarketSpot, SpotStatus: "marked-for-termination", State: "running", AvailabilityZone: "eu-west-1a"},
+	})
+
+	expected := []string{
+		instancesHeader,
+		"i-1 | ami-1 | m3.large | spot (marked-for-termination) | running | eu-west-1a | - | - | - | - | -",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d: %q", len(expected), len(lines), lines)
+	}
+	for index := range expected {
+		if lines[index] != expected[index] {
+			t.Errorf("expected line '%s', got '%s'", expected[index], lines[index])
+		}
+	}
+}
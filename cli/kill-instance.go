@@ -43,50 +43,65 @@ func runKillInstance(args []string) (exit int) {
 	swarmName := args[0]
 	instanceID := args[1]
 
+	event := startEvent("kill-instance", swarmName)
+	defer func() { event.fire(exit) }()
+
+	release, err := lockSwarm(event)
+	if err != nil {
+		return event.exitError("couldn't lock swarm:", err)
+	}
+	defer release()
+
 	s, err := swarmService.Get(swarmName, swarm.AWS)
 	if err != nil {
-		return exitError(fmt.Sprintf("couldn't get instances of swarm: %s", swarmName), err)
+		return event.exitError(fmt.Sprintf("couldn't get instances of swarm: %s", swarmName), err)
+	}
+	event.setSwarm(s)
+
+	if s.IsProtected() {
+		return protectedError(event, "kill instance", swarmName)
 	}
 
 	instances, err := s.GetInstances()
 	if err != nil {
-		return exitError(err)
+		return event.exitError(err)
 	}
 
 	killableInstance, err := swarmtypes.FindInstanceById(instances, instanceID)
 	if err != nil {
-		return exitError(errgo.WithCausef(err, nil, "failed to find provided instance: %s", instanceID))
+		return event.exitError(errgo.WithCausef(err, nil, "failed to find provided instance: %s", instanceID))
 	}
 
 	runningInstances := swarmtypes.FilterInstanceById(instances, instanceID)
 	if len(runningInstances) == 0 {
-		return exitError(errgo.Newf("no more instances left in swarm %s. Cannot update Fleet DNS entry", swarmName))
+		return event.exitError(errgo.Newf("no more instances left in swarm %s. Cannot update Fleet DNS entry", swarmName))
 	}
 
 	if !ignoreQuorumCheck {
 		etcdQuorumID, err := ssh.GetEtcd2MemberName(killableInstance.PublicIPAddress)
 		if err != nil {
-			return exitError(errgo.WithCausef(err, nil, "ssh: failed to check quorum member list: %v", err))
+			return event.exitError(errgo.WithCausef(err, nil, "ssh: failed to check quorum member list: %v", err))
 		}
 
 		if etcdQuorumID != "" {
-			return exitError(errgo.Newf("Instance %s seems to be part of the etcd quorum. Please remove it beforehand. See %s", killableInstance.Id, etcdDocsLink))
+			return event.exitError(errgo.Newf("Instance %s seems to be part of the etcd quorum. Please remove it beforehand. See %s", killableInstance.Id, etcdDocsLink))
 		}
 	}
 
 	if err = s.KillInstance(killableInstance); err != nil {
-		return exitError(errgo.WithCausef(err, nil, "failed to kill instance: %s", instanceID))
+		return event.exitError(errgo.WithCausef(err, nil, "failed to kill instance: %s", instanceID))
 	}
 
-	if changed, err := dns.Update(dnsService, viperConfig.getDNSNamingPattern(), s, runningInstances); err != nil {
-		return exitError(errgo.WithCausef(err, nil, "failed to update dns records"))
-	} else if !changed {
-		return exitError(errgo.Newf("DNS not changed. Couldn't find valid publid DNS name"))
+	event.Instances = []string{killableInstance.Id}
+
+	pattern := viperConfig.getDNSNamingPattern()
+	changes, err := dns.Update(dnsService, pattern, s, runningInstances)
+	if err != nil {
+		return event.exitError(errgo.WithCausef(err, nil, "failed to update dns records"))
 	}
+	event.DNSEntries = dns.Names(changes)
 
 	fmt.Printf(killInstanceSuccessMessage, killableInstance.Id, etcdDocsLink)
 
-	fireNotification()
-
 	return 0
 }
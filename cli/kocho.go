@@ -4,9 +4,11 @@ package cli
 import (
 	"fmt"
 	"os"
+	"os/user"
 	"text/tabwriter"
 
 	"github.com/giantswarm/kocho/dns"
+	"github.com/giantswarm/kocho/lock"
 	"github.com/giantswarm/kocho/provider/aws/sdk"
 	"github.com/giantswarm/kocho/swarm"
 
@@ -25,6 +27,9 @@ var (
 	// top level commands
 	commands []*Command
 
+	// the command being run
+	activeCommand *Command
+
 	// flags used by all commands
 	globalFlags = struct {
 		Debug   bool
@@ -66,8 +71,14 @@ func init() {
 	globalFlagset.String("dns-public", dns.DefaultNamingPattern.Public, "template for the public dns record")
 	globalFlagset.String("dns-private", dns.DefaultNamingPattern.Private, "template for the private dns record")
 	globalFlagset.String("dns-fleet", dns.DefaultNamingPattern.Fleet, "template for the fleet dns record")
+	globalFlagset.String("dns-fleet-records", dns.DefaultNamingPattern.FleetRecords, "kind of records of the fleet dns entry: cname to one instance, or public-ip or private-ip for an A record per healthy instance")
+
+	globalFlagset.String("audit-log", ConfigHomePath+"kocho/audit.log", "file or s3://<bucket>/<key> object to record commands changing swarms in, empty to disable")
+	globalFlagset.String("lock", ConfigHomePath+"kocho/locks", "directory or dynamodb:<table> to keep the locks of swarms being changed in, empty to disable")
+	globalFlagset.Duration("lock-lease", lock.DefaultLease, "how long a lock of a swarm is held at most")
 
 	sdk.DefaultSessionProvider.RegisterFlagSet(globalFlagset)
+	globalFlagset.String("regions", "", "comma separated list of AWS regions to list and look up swarms in, or 'all' - defaults to --aws-region")
 }
 
 // Command describes a command that can be run.
@@ -87,13 +98,23 @@ func NewKochoCmd() {
 	out.Init(os.Stdout, 0, 8, 1, '\t', 0)
 	commands = []*Command{
 		cmdCreate,
+		cmdClone,
 		cmdDestroy,
+		cmdProtect,
+		cmdUnprotect,
 		cmdInstances,
 		cmdKillInstance,
 		cmdEtcd,
 		cmdStatus,
+		cmdDescribe,
+		cmdDiff,
+		cmdUpdate,
 		cmdList,
+		cmdTopology,
+		cmdReap,
 		cmdWaitUntil,
+		cmdHistory,
+		cmdUnlock,
 		cmdDns,
 		cmdHelp,
 		cmdVersion,
@@ -147,7 +168,13 @@ func Execute() {
 	}
 
 	// Init global stuff for the CLI, e.g. the swarm service
+	if err := sdk.DefaultSessionProvider.Init(); err != nil {
+		os.Exit(exitError("failed to set up aws session:", err))
+	}
 	dnsService = newDNSService(viperConfig)
+	swarmConfig.Regions = viperConfig.getRegions()
+	swarmConfig.KochoVersion = projectVersion
+	swarmConfig.Creator = currentUser()
 	swarmService = swarm.NewService(swarmConfig, swarmDependencies)
 
 	// Copy command specific flags into viper
@@ -161,6 +188,7 @@ func Execute() {
 		fmt.Println(err.Error())
 		os.Exit(2)
 	}
+	activeCommand = cmd
 
 	os.Exit(cmd.Run(cmd.Flags.Args()))
 }
@@ -177,6 +205,15 @@ func getFlags(flagset *pflag.FlagSet) (flags []*pflag.Flag) {
 	return
 }
 
+// currentUser returns the name of the user running kocho, falling back to $USER
+// where it cannot be looked up.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
 func exitError(args ...interface{}) (exit int) {
 	fmt.Fprintln(os.Stderr, args...)
 	return 1
@@ -7,16 +7,25 @@ import (
 	"github.com/ryanuber/columnize"
 )
 
-var cmdList = &Command{
-	Name:        "list",
-	Description: "List all swarms",
-	Summary:     "List all existing swarms.",
-	Run:         runList,
+var (
+	cmdList = &Command{
+		Name:        "list",
+		Usage:       "[--all-stacks]",
+		Description: "List all swarms. By default only stacks created by kocho are listed, use --all-stacks to list every stack",
+		Summary:     "List all existing swarms.",
+		Run:         runList,
+	}
+
+	flagListAllStacks bool
+)
+
+func init() {
+	cmdList.Flags.BoolVar(&flagListAllStacks, "all-stacks", false, "also list stacks that were not created by kocho")
 }
 
 const (
-	swarmListHeader = "Name | Type | Created"
-	swarmListScheme = "%s | %s | %s"
+	swarmListHeader = "Name | Type | Region | Created | Expires"
+	swarmListScheme = "%s | %s | %s | %s | %s"
 )
 
 func runList(args []string) (exit int) {
@@ -24,14 +33,31 @@ func runList(args []string) (exit int) {
 		return exitError("too many arguments")
 	}
 
-	swarms, err := swarmService.List()
+	swarms, err := swarmService.List(flagListAllStacks)
 	if err != nil {
 		return exitError("couldn't list swarms", err)
 	}
 	lines := []string{swarmListHeader}
 	for _, s := range swarms {
-		lines = append(lines, fmt.Sprintf(swarmListScheme, s.Name, s.Type, s.Created.Format(time.RFC822)))
+		// A single swarm with an invalid spec doesn't hide the others
+		remaining := "?"
+		if expires, err := s.GetExpiryTime(); err == nil {
+			remaining = formatRemainingLifetime(expires, time.Now())
+		}
+		lines = append(lines, fmt.Sprintf(swarmListScheme, s.Name, s.Type, s.Region, s.Created.Format(time.RFC822), remaining))
 	}
 	fmt.Println(columnize.SimpleFormat(lines))
 	return 0
 }
+
+// formatRemainingLifetime returns the time left until the given expiry time.
+func formatRemainingLifetime(expires, now time.Time) string {
+	if expires.IsZero() {
+		return "never"
+	}
+	if !expires.After(now) {
+		return "expired"
+	}
+	remaining := expires.Sub(now) / time.Minute * time.Minute
+	return "in " + remaining.String()
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatRemainingLifetime(t *testing.T) {
+	now := time.Date(2016, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Expires  time.Time
+		Expected string
+	}{
+		{time.Time{}, "never"},
+		{now.Add(-time.Hour), "expired"},
+		{now, "expired"},
+		{now.Add(47*time.Hour + 12*time.Minute + 30*time.Second), "in 47h12m0s"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := formatRemainingLifetime(testCase.Expires, now); actual != testCase.Expected {
+			t.Errorf("expected '%s' for expiry %v, got '%s'", testCase.Expected, testCase.Expires, actual)
+		}
+	}
+}
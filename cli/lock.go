@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/lock"
+)
+
+// lockSwarm locks the swarm of the event for its command. The lock is renewed
+// while the command runs, so waiting for long stack operations doesn't let its
+// lease expire. The returned function releases the lock again. Failing to do
+// so is reported, but doesn't fail the command, as the lock expires eventually.
+func lockSwarm(e *commandEvent) (func(), error) {
+	locker := viperConfig.getLocker()
+	if locker == nil {
+		return func() {}, nil
+	}
+
+	l, err := locker.Acquire(e.Swarm, e.Command)
+	if lock.IsLocked(err) {
+		return nil, errgo.Newf("%v. Use 'kocho unlock --force %s' if it is stale", err, e.Swarm)
+	} else if err != nil {
+		return nil, err
+	}
+
+	stop := l.KeepRenewed(func(err error) {
+		exitError("failed to renew lock of swarm:", err)
+	})
+	return func() {
+		stop()
+		if err := l.Release(); err != nil {
+			exitError("failed to release lock of swarm:", err)
+		}
+	}, nil
+}
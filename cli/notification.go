@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/kocho/notification"
+	"github.com/giantswarm/kocho/swarm"
+)
+
+// newNotifier returns the notifiers configured in the config file.
+func newNotifier() (notification.Notifiers, error) {
+	config, err := getNotificationConfig()
+	if err != nil {
+		return nil, err
+	}
+	return notification.NewNotifiers(config)
+}
+
+// getNotificationConfig returns the notification configuration of the config
+// file. Without Slack configured there, the slack.conf file is used if it exists.
+func getNotificationConfig() (notification.Configuration, error) {
+	config, err := viperConfig.getNotificationConfig()
+	if err != nil {
+		return config, err
+	}
+
+	if config.Slack == nil {
+		slackConfiguration, err := notification.LoadLegacySlackConfig()
+		if err == nil {
+			config.Slack = &slackConfiguration
+		} else if !notification.IsNotConfigured(err) {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+// newEvent returns an event of the given command, for the current invocation of kocho.
+func newEvent(command string) notification.Event {
+	return notification.NewEvent(command, os.Args, currentUser(), projectVersion, projectBuild)
+}
+
+// commandEvent records the outcome of a command changing a swarm to notify about.
+type commandEvent struct {
+	notification.Event
+	start   time.Time
+	stackId string
+
+	// notifier is shared by all notifications of the event, so progress can
+	// be related to the start of the command
+	notifier    notification.Notifiers
+	notifierErr error
+}
+
+// startEvent starts the event of the given command changing the named swarm.
+func startEvent(command, swarmName string) *commandEvent {
+	notifier, err := newNotifier()
+	e := &commandEvent{
+		Event:       newEvent(command),
+		start:       time.Now(),
+		notifier:    notifier,
+		notifierErr: err,
+	}
+	e.Swarm = swarmName
+	e.Provider = swarm.AWS.String()
+	return e
+}
+
+// setSwarm records the type, region and ID of the swarm.
+func (e *commandEvent) setSwarm(s *swarm.Swarm) {
+	e.SwarmType = s.Type
+	e.Region = s.Region
+	e.stackId = s.Id
+}
+
+// exitError records the error as the error of the event, and prints it.
+func (e *commandEvent) exitError(args ...interface{}) (exit int) {
+	e.Error = strings.TrimSpace(fmt.Sprintln(args...))
+	return exitError(args...)
+}
+
+// notifyStart notifies that the long running command of the event started.
+func (e *commandEvent) notifyStart() {
+	if e.notifierErr == nil {
+		reportNotificationError(e.notifier.NotifyStart(e.Event))
+	}
+}
+
+// notifyProgress notifies about the progress of the command of the event.
+func (e *commandEvent) notifyProgress(format string, args ...interface{}) {
+	if e.notifierErr == nil {
+		reportNotificationError(e.notifier.NotifyProgress(e.Event, fmt.Sprintf(format, args...)))
+	}
+}
+
+// fire records the event in the audit log and notifies about it. The event
+// succeeded if exit is zero.
+func (e *commandEvent) fire(exit int) {
+	e.Success = exit == 0
+	e.Duration = time.Since(e.start)
+	recordAuditEntry(e)
+
+	err := e.notifierErr
+	if err == nil {
+		err = e.notifier.Notify(e.Event)
+	}
+	reportNotificationError(err)
+}
+
+// reportNotificationError prints the error of sending a notification, if any.
+func reportNotificationError(err error) {
+	if err == nil {
+		return
+	}
+
+	if notification.IsNotConfigured(err) {
+		exitError("Notifications not configured. Use 'kocho slack init' or configure notifications in kocho.yml")
+	} else if notification.IsInvalidConfiguration(err) {
+		exitError("Invalid configuration file:", err)
+	} else {
+		exitError("failed to send message:", err)
+	}
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/kocho/swarm"
+)
+
+var (
+	cmdProtect = &Command{
+		Name:        "protect",
+		Usage:       "<swarm>",
+		Description: "Protect a swarm against deletion. Protected swarms can't be destroyed and their instances can't be killed until they are unprotected",
+		Summary:     "Protect a swarm against deletion",
+		Run:         runProtect,
+	}
+
+	cmdUnprotect = &Command{
+		Name:        "unprotect",
+		Usage:       "<swarm>",
+		Description: "Remove the deletion protection of a swarm",
+		Summary:     "Remove the deletion protection of a swarm",
+		Run:         runUnprotect,
+	}
+)
+
+func runProtect(args []string) (exit int) {
+	return setProtected("protect", args, true)
+}
+
+func runUnprotect(args []string) (exit int) {
+	return setProtected("unprotect", args, false)
+}
+
+func setProtected(command string, args []string, protected bool) (exit int) {
+	if len(args) == 0 {
+		return exitError(fmt.Sprintf("no Swarm given. Usage: kocho %s <swarm>", command))
+	} else if len(args) > 1 {
+		return exitError(fmt.Sprintf("too many arguments. Usage: kocho %s <swarm>", command))
+	}
+	swarmName := args[0]
+
+	event := startEvent(command, swarmName)
+	defer func() { event.fire(exit) }()
+
+	release, err := lockSwarm(event)
+	if err != nil {
+		return event.exitError("couldn't lock swarm:", err)
+	}
+	defer release()
+
+	s, err := swarmService.Get(swarmName, swarm.AWS)
+	if err != nil {
+		return event.exitError(fmt.Sprintf("couldn't find swarm: %s", swarmName), err)
+	}
+	event.setSwarm(s)
+
+	if err := s.SetProtected(protected); err != nil {
+		return event.exitError(fmt.Sprintf("couldn't %s swarm: %s", command, swarmName), err)
+	}
+
+	if protected {
+		fmt.Printf("swarm %s is protected against deletion\n", swarmName)
+	} else {
+		fmt.Printf("swarm %s is no longer protected against deletion\n", swarmName)
+	}
+	return 0
+}
+
+// protectedError records and returns the error shown when trying to modify a protected swarm.
+func protectedError(event *commandEvent, action, swarmName string) (exit int) {
+	return event.exitError(fmt.Sprintf("couldn't %s: swarm %s is protected. Use 'kocho unprotect %s' first", action, swarmName, swarmName))
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ryanuber/columnize"
+
+	"github.com/giantswarm/kocho/dns"
+	"github.com/giantswarm/kocho/swarm"
+)
+
+var (
+	cmdReap = &Command{
+		Name:        "reap",
+		Usage:       "[--dry-run]",
+		Description: "Destroy all swarms that are past their expiry time, as given by --ttl on create, and delete their DNS entries. Protected swarms and primary swarms with secondaries attached are skipped",
+		Summary:     "Destroy expired swarms",
+		Run:         runReap,
+	}
+
+	flagReapDryRun bool
+)
+
+func init() {
+	cmdReap.Flags.BoolVar(&flagReapDryRun, "dry-run", false, "only list the swarms that would be destroyed")
+}
+
+func runReap(args []string) (exit int) {
+	if len(args) > 0 {
+		return exitError("too many arguments. Usage: kocho reap [--dry-run]")
+	}
+
+	expired, err := findExpiredSwarms(time.Now())
+	if err != nil {
+		return exitError("couldn't find expired swarms", err)
+	}
+
+	lines := []string{"Name | Type | Region | Expired"}
+	for _, e := range expired {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s", e.swarm.Name, e.swarm.Type, e.swarm.Region, e.expires.Format(time.RFC822)))
+	}
+	fmt.Println(columnize.SimpleFormat(lines))
+
+	if flagReapDryRun || len(expired) == 0 {
+		return 0
+	}
+
+	failed := 0
+	for _, e := range expired {
+		if reapSwarm(e.swarm) != 0 {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return exitError(fmt.Sprintf("failed to reap %d of %d expired swarms", failed, len(expired)))
+	}
+	return 0
+}
+
+// reapSwarm destroys the swarm and deletes its DNS entries, and notifies about
+// the outcome. The swarm may have been protected, or secondaries attached to
+// it, before it was locked, so this is checked again.
+func reapSwarm(s *swarm.Swarm) (exit int) {
+	event := startEvent("reap", s.Name)
+	event.setSwarm(s)
+	defer func() { event.fire(exit) }()
+
+	release, err := lockSwarm(event)
+	if err != nil {
+		return event.exitError("couldn't lock swarm:", err)
+	}
+	defer release()
+
+	if s, err = swarmService.Get(s.Name, swarm.AWS); err != nil {
+		return event.exitError(fmt.Sprintf("couldn't find swarm: %s", event.Swarm), err)
+	}
+	if s.IsProtected() {
+		return protectedError(event, "reap swarm", s.Name)
+	}
+	if names, err := attachedSecondaries(s); err != nil {
+		return event.exitError(fmt.Sprintf("couldn't find secondary swarms of swarm: %s", s.Name), err)
+	} else if len(names) > 0 {
+		return event.exitError(fmt.Sprintf("couldn't reap swarm: %s is the primary swarm of %s. Destroy them first", s.Name, strings.Join(names, ", ")))
+	}
+
+	if err := s.Destroy(); err != nil {
+		return event.exitError(fmt.Sprintf("couldn't delete swarm: %s", s.Name), err)
+	}
+
+	changes, err := dns.DeleteEntries(dnsService, viperConfig.getDNSNamingPattern(), s.Name)
+	if err != nil {
+		return event.exitError(fmt.Sprintf("couldn't delete dns entries of swarm: %s", s.Name), err)
+	}
+	event.DNSEntries = dns.Names(changes)
+
+	fmt.Printf("triggered swarm %s deletion\n", s.Name)
+	return 0
+}
+
+type expiredSwarm struct {
+	swarm   *swarm.Swarm
+	expires time.Time
+}
+
+// findExpiredSwarms returns all swarms with an expiry time before now, except
+// for protected ones. Destroying a primary swarm breaks its secondaries, so
+// primaries with secondaries attached are skipped with a warning, as are swarms
+// whose expiry time can't be determined.
+func findExpiredSwarms(now time.Time) ([]expiredSwarm, error) {
+	swarms, err := swarmService.List(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []expiredSwarm
+	for _, s := range swarms {
+		expires, err := s.GetExpiryTime()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping swarm %s, couldn't get its expiry time: %v\n", s.Name, err)
+			continue
+		}
+		if expires.IsZero() || !expires.Before(now) || s.IsProtected() {
+			continue
+		}
+
+		names, err := attachedSecondaries(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping swarm %s, couldn't find its secondary swarms: %v\n", s.Name, err)
+			continue
+		}
+		if len(names) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: skipping swarm %s, it is the primary swarm of %s\n", s.Name, strings.Join(names, ", "))
+			continue
+		}
+
+		expired = append(expired, expiredSwarm{swarm: s, expires: expires})
+	}
+	return expired, nil
+}
+
+// attachedSecondaries returns the names of the secondary swarms attached to
+// the swarm, if it is a primary swarm.
+func attachedSecondaries(s *swarm.Swarm) ([]string, error) {
+	if s.Type != "primary" {
+		return nil, nil
+	}
+
+	secondaries, err := swarmService.GetSecondaries(s)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, secondary := range secondaries {
+		names = append(names, secondary.Name)
+	}
+	return names, nil
+}
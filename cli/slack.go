@@ -56,11 +56,16 @@ func runSlack(args []string) (exit int) {
 			slackConfiguration.NotificationChannel = "#" + slackConfiguration.NotificationChannel
 		}
 
-		if err := notification.WriteConfig(slackConfiguration); err != nil {
-			return exitError("failed to write configuration: ", err)
-		}
+		fmt.Printf("\nAdd the following to your kocho.yml:\n\n%s", formatSlackConfig(slackConfiguration))
 	case "test":
-		if err := notification.SendMessage(projectVersion, projectBuild); err != nil {
+		config, err := getNotificationConfig()
+		if err == nil && config.Slack == nil {
+			err = notification.ErrNotConfigured
+		}
+		if err == nil {
+			err = sendSlackTestMessage(config)
+		}
+		if err != nil {
 			if notification.IsNotConfigured(err) {
 				exitError("Notifications not configured. Use 'kocho slack init'")
 			} else if notification.IsInvalidConfiguration(err) {
@@ -76,14 +81,37 @@ func runSlack(args []string) (exit int) {
 	return 0
 }
 
-func fireNotification() {
-	if err := notification.SendMessage(projectVersion, projectBuild); err != nil {
-		if notification.IsNotConfigured(err) {
-			exitError("Notifications not configured. Use 'kocho slack init'")
-		} else if notification.IsInvalidConfiguration(err) {
-			exitError("Invalid configuration file: %v", err)
-		} else {
-			exitError("failed to send message: %v", err)
-		}
+// sendSlackTestMessage sends a test event to the Slack notifier of the configuration.
+func sendSlackTestMessage(config notification.Configuration) error {
+	templates, err := notification.NewTemplates(config.Templates)
+	if err != nil {
+		return err
+	}
+	notifier, err := notification.NewSlackNotifier(*config.Slack, templates)
+	if err != nil {
+		return err
+	}
+
+	event := newEvent("slack")
+	event.Success = true
+	return notifier.Notify(event)
+}
+
+// formatSlackConfig returns the notifications section of the kocho
+// configuration file for the given Slack configuration.
+func formatSlackConfig(config notification.SlackConfiguration) string {
+	lines := []string{
+		"notifications:",
+		"  slack:",
+		fmt.Sprintf("    token: %q", config.Token),
+		fmt.Sprintf("    username: %q", config.Username),
+	}
+	if config.NotificationUsername != "" {
+		lines = append(lines, fmt.Sprintf("    notification_username: %q", config.NotificationUsername))
+	}
+	if config.EmojiIcon != "" {
+		lines = append(lines, fmt.Sprintf("    emoji_icon: %q", config.EmojiIcon))
 	}
+	lines = append(lines, fmt.Sprintf("    notification_channel: %q", config.NotificationChannel))
+	return strings.Join(lines, "\n") + "\n"
 }
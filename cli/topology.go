@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ryanuber/columnize"
+)
+
+var cmdTopology = &Command{
+	Name:        "topology",
+	Description: "List all primary swarms with the secondary swarms attached to them",
+	Summary:     "List primary swarms with their secondaries",
+	Run:         runTopology,
+}
+
+const (
+	topologyHeader = "Primary | Secondary | Region"
+	topologyScheme = "%s | %s | %s"
+)
+
+func runTopology(args []string) (exit int) {
+	if len(args) > 0 {
+		return exitError("too many arguments")
+	}
+
+	topologies, detached, err := swarmService.GetTopology()
+	if err != nil {
+		return exitError("couldn't get topology of swarms", err)
+	}
+
+	lines := []string{topologyHeader}
+	for _, t := range topologies {
+		lines = append(lines, fmt.Sprintf(topologyScheme, t.Primary.Name, "-", t.Primary.Region))
+		for _, secondary := range t.Secondaries {
+			lines = append(lines, fmt.Sprintf(topologyScheme, "", secondary.Name, secondary.Region))
+		}
+	}
+	for _, secondary := range detached {
+		lines = append(lines, fmt.Sprintf(topologyScheme, "(unknown)", secondary.Name, secondary.Region))
+	}
+	fmt.Println(columnize.SimpleFormat(lines))
+	return 0
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		A, B     string
+		Expected string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"", "a\n", "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n"},
+		{"a\nb\nc\n", "a\nx\nc\n", "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n",
+			"--- old\n+++ new\n@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n",
+		},
+		{
+			"1\nx\n3\n4\n5\n6\n7\n8\n9\n10\nx\n12\n",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-x\n+2\n 3\n 4\n 5\n@@ -8,5 +8,5 @@\n 8\n 9\n 10\n-x\n+11\n 12\n",
+		},
+	}
+
+	for index, testCase := range testCases {
+		if actual := unifiedDiff("old", "new", testCase.A, testCase.B); actual != testCase.Expected {
+			t.Errorf("test %d: expected\n%s\ngot\n%s", index, testCase.Expected, actual)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/kocho/lock"
+)
+
+var (
+	cmdUnlock = &Command{
+		Name:        "unlock",
+		Usage:       "[--force] <swarm>",
+		Description: "Remove the lock of a swarm left behind by a command that didn't finish. Without --force, only expired locks and locks acquired by you on this host are removed",
+		Summary:     "Remove the lock of a swarm",
+		Run:         runUnlock,
+	}
+
+	flagUnlockForce bool
+)
+
+func init() {
+	cmdUnlock.Flags.BoolVar(&flagUnlockForce, "force", false, "also remove locks held by others")
+}
+
+func runUnlock(args []string) (exit int) {
+	if len(args) == 0 {
+		return exitError("no Swarm given. Usage: kocho unlock [--force] <swarm>")
+	} else if len(args) > 1 {
+		return exitError("too many arguments. Usage: kocho unlock [--force] <swarm>")
+	}
+	swarmName := args[0]
+
+	locker := viperConfig.getLocker()
+	if locker == nil {
+		return exitError("locking is disabled. Use --lock or set lock in kocho.yml")
+	}
+
+	event := startEvent("unlock", swarmName)
+	defer func() { event.fire(exit) }()
+
+	info, err := locker.Unlock(swarmName, flagUnlockForce)
+	if lock.IsLocked(err) {
+		return event.exitError(fmt.Sprintf("%v. Use --force to remove it anyway", err))
+	} else if err != nil {
+		return event.exitError(fmt.Sprintf("couldn't unlock swarm: %s", swarmName), err)
+	}
+
+	if info == nil {
+		fmt.Printf("swarm %s is not locked\n", swarmName)
+	} else {
+		fmt.Printf("removed lock of swarm %s held by %s\n", swarmName, info.Holder())
+	}
+	return 0
+}
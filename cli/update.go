@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/juju/errgo"
+	"github.com/ryanuber/columnize"
+
+	"github.com/giantswarm/kocho/dns"
+	"github.com/giantswarm/kocho/provider"
+	"github.com/giantswarm/kocho/swarm"
+)
+
+var (
+	cmdUpdate = &Command{
+		Name:        "update",
+		Usage:       "[create flags] [--preview] [--force] <swarm>",
+		Description: "Update a swarm to its recorded spec and the current templates. Create flags given on the command line override the recorded spec. The changes are shown and confirmed before they are executed, use --preview to only show them",
+		Summary:     "Update a swarm",
+		Run:         runUpdate,
+	}
+
+	flagUpdatePreview bool
+	flagUpdateForce   bool
+)
+
+const (
+	changeSetHeader = "Action | Resource | Type | PhysicalId | Replacement"
+	changeSetScheme = "%s | %s | %s | %s | %s"
+)
+
+func init() {
+	registerCreateFlags(&cmdUpdate.Flags)
+
+	cmdUpdate.Flags.BoolVar(&flagUpdatePreview, "preview", false, "only show the changes, don't execute them")
+	cmdUpdate.Flags.BoolVar(&flagUpdateForce, "force", false, "do not confirm the update, unless members of the etcd quorum are replaced")
+	cmdUpdate.Flags.BoolVar(&sharedFlags.NoBlock, "no-block", false, "do not wait until the swarm has been updated before exiting")
+}
+
+func runUpdate(args []string) (exit int) {
+	if len(args) == 0 {
+		return exitError("no Swarm given. Usage: kocho update <swarm>")
+	} else if len(args) > 1 {
+		return exitError("too many arguments. Usage: kocho update <swarm>")
+	}
+	swarmName := args[0]
+
+	// Previews don't change the swarm, so they are neither recorded,
+	// notified nor locked
+	event := startEvent("update", swarmName)
+	if !flagUpdatePreview {
+		defer func() { event.fire(exit) }()
+
+		release, err := lockSwarm(event)
+		if err != nil {
+			return event.exitError("couldn't lock swarm:", err)
+		}
+		defer release()
+	}
+
+	s, err := swarmService.Get(swarmName, swarm.AWS)
+	if err != nil {
+		return event.exitError(fmt.Sprintf("couldn't find swarm: %s", swarmName), err)
+	}
+	event.setSwarm(s)
+
+	spec, err := s.GetSpec()
+	if err == provider.ErrNotFound {
+		return event.exitError(fmt.Sprintf("couldn't update swarm: %s was created without recording its spec", swarmName))
+	} else if err != nil {
+		return event.exitError(fmt.Sprintf("couldn't get spec of swarm: %s", swarmName), err)
+	}
+
+	flags := applyCreateFlagOverrides(spec.CreateFlags, viperConfig.newViperCreateFlags(), &cmdUpdate.Flags)
+
+	changeSet, err := swarmService.PlanUpdate(s, flags)
+	if errgo.Cause(err) == provider.ErrNotFound {
+		return event.exitError(fmt.Sprintf("couldn't update swarm: %s was created without recording its spec", swarmName))
+	} else if err != nil {
+		return event.exitError(fmt.Sprintf("couldn't prepare update of swarm: %s", swarmName), err)
+	}
+
+	// The change set is deleted unless it was executed, so declined or failed
+	// updates don't leave it behind
+	executed := false
+	defer func() {
+		if executed {
+			return
+		}
+		if err := s.DeleteChangeSet(changeSet); err != nil {
+			exit = event.exitError("couldn't delete change set", err)
+		}
+	}()
+
+	if len(changeSet.Changes) == 0 {
+		fmt.Printf("swarm %s is up to date\n", swarmName)
+		return 0
+	}
+
+	replacesQuorumMembers := false
+	lines := []string{changeSetHeader}
+	for _, c := range changeSet.Changes {
+		replacement := orDash(c.Replacement)
+		if c.ReplacesQuorumMember {
+			replacement += " (destroys etcd quorum member)"
+			replacesQuorumMembers = true
+		}
+		lines = append(lines, fmt.Sprintf(changeSetScheme, c.Action, c.LogicalId, c.Type, orDash(c.PhysicalId), replacement))
+	}
+	fmt.Println(columnize.SimpleFormat(lines))
+
+	if flagUpdatePreview {
+		return 0
+	}
+
+	// Losing quorum members can break the etcd cluster, so this is confirmed even with --force
+	if replacesQuorumMembers {
+		if err := confirmInterruptible(fmt.Sprintf("the update destroys members of the etcd quorum of '%s'. Enter the name of the swarm to confirm:", swarmName), swarmName); err != nil {
+			return event.exitError("failed to read from stdin", err)
+		}
+	} else if !flagUpdateForce {
+		if err := confirmInterruptible(fmt.Sprintf("are you sure you want to update '%s'? Enter yes:", swarmName), "yes"); err != nil {
+			return event.exitError("failed to read from stdin", err)
+		}
+	}
+
+	event.notifyStart()
+
+	if err := s.ExecuteChangeSet(changeSet); err != nil {
+		return event.exitError(fmt.Sprintf("couldn't update swarm: %s", swarmName), err)
+	}
+	executed = true
+
+	if !sharedFlags.NoBlock {
+		event.notifyProgress("Triggered update of swarm %s with %d changes, waiting for it to complete", swarmName, len(changeSet.Changes))
+		if err := s.WaitUntil(provider.StatusUpdated); err != nil {
+			return event.exitError("couldn't find out if swarm was updated correctly", err)
+		}
+		event.notifyProgress("Swarm %s updated, updating DNS entries", swarmName)
+
+		// Instances may have been replaced or added by the update
+		instances, err := s.GetInstances()
+		if err != nil {
+			return event.exitError(fmt.Sprintf("couldn't get instances of swarm: %s", swarmName), err)
+		}
+		changes, err := dns.Update(dnsService, viperConfig.getDNSNamingPattern(), s, instances)
+		if err != nil {
+			return event.exitError("couldn't update dns entries", err)
+		}
+		event.DNSEntries = dns.Names(changes)
+	} else {
+		fmt.Printf("triggered swarm %s update. Run 'kocho dns %s' once it completed to update its DNS entries\n", swarmName, swarmName)
+	}
+
+	return 0
+}
+
+// confirmInterruptible asks the question like confirmInput, but returns an
+// error if the user interrupts it, instead of exiting without cleaning up.
+func confirmInterruptible(question, expected string) error {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	answer := make(chan error, 1)
+	go func() { answer <- confirmInput(question, expected) }()
+
+	select {
+	case err := <-answer:
+		return err
+	case <-interrupts:
+		fmt.Println()
+		return errgo.New("interrupted")
+	}
+}
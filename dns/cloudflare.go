@@ -4,16 +4,28 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/giantswarm/kocho/swarm"
-
 	"github.com/crackcomm/cloudflare"
 	"github.com/juju/errgo"
 	"golang.org/x/net/context"
 )
 
+const (
+	// automaticTTL lets CloudFlare choose the TTL of a record.
+	automaticTTL = 1
+)
+
 // CloudFlareConfig provides the static configuration for a CloudFlareDNS service.
 type CloudFlareConfig struct {
 	Email, Token string
+
+	// Records holds the options of the records of each type of entry, e.g. fleet.
+	Records map[string]RecordOptions `mapstructure:"records"`
+}
+
+// RecordOptions configures the DNS records of a type of entry.
+type RecordOptions struct {
+	TTL     int  `mapstructure:"ttl"`     // TTL is the TTL of the records in seconds. Zero lets CloudFlare choose it.
+	Proxied bool `mapstructure:"proxied"` // Proxied is whether traffic goes through CloudFlare.
 }
 
 // NewCloudFlareDNS creates a new CloudFlareDNS object based on the given config.
@@ -22,6 +34,11 @@ func NewCloudFlareDNS(config CloudFlareConfig) *CloudFlareDNS {
 	if config.Email == "" || config.Token == "" {
 		panic("Cloudflare DNS requires email and token")
 	}
+	for entryType := range config.Records {
+		if !isEntryType(entryType) {
+			panic(fmt.Sprintf("Cloudflare DNS records configured for unknown entry type: %s", entryType))
+		}
+	}
 	return &CloudFlareDNS{
 		CloudFlareConfig: config,
 	}
@@ -33,104 +50,73 @@ type CloudFlareDNS struct {
 
 	mutex   sync.Mutex
 	_client *cloudflare.Client
+
+	zonesMutex sync.Mutex
+	zones      map[string]*cloudflare.Zone
 }
 
-// createSwarmEntries creates DNS entries, given a Swarm and Entries to create.
-func (cli *CloudFlareDNS) createSwarmEntries(s *swarm.Swarm, e *Entries) error {
-	ctx := context.TODO()
+// cloudFlareRecords manages DNS records, like the records API of the CloudFlare client.
+type cloudFlareRecords interface {
+	Create(ctx context.Context, record *cloudflare.Record) error
+	Patch(ctx context.Context, record *cloudflare.Record) error
+	Delete(ctx context.Context, zoneID, recordID string) error
+}
 
-	zone, err := cli.findZone(ctx, e.Zone)
-	if err != nil {
-		return errgo.Mask(err, errgo.Any)
-	}
+// managesRecords returns true, as CloudFlare keeps the records of swarms.
+func (cli *CloudFlareDNS) managesRecords() bool {
+	return true
+}
 
-	privateDns, err := s.GetPrivateDNS()
-	if err != nil {
-		return fmt.Errorf("Couldn't get private dns for swarm: %s - %v", s.Name, err)
-	}
-	instances, err := s.GetInstances()
+// records returns the records with the given names in the zone.
+func (cli *CloudFlareDNS) records(zone string, names []string) ([]Record, error) {
+	ctx := context.TODO()
+
+	_, existing, err := cli.listRecords(ctx, zone)
 	if err != nil {
-		return fmt.Errorf("Failed fetch list of instances for swarm: %s - %v", s.Name, err)
-	}
-	if len(instances) < 1 {
-		return fmt.Errorf("Couldn't get swarm instances of: %s - %v", s.Name, err)
+		return nil, errgo.Mask(err, errgo.Any)
 	}
 
-	if s.Type != "primary" {
-		publicDns, err := s.GetPublicDNS()
-		if err != nil {
-			return fmt.Errorf("Couldn't get public dns for swarm: %s - %v", s.Name, err)
-		}
-		if err := cli.createRecord(ctx, zone.ID, e.Catchall, publicDns); err != nil {
-			return fmt.Errorf("Couldn't create catchall dns entry: %s %s - %v", e.Catchall, publicDns, err)
-		}
-		if err := cli.createRecord(ctx, zone.ID, e.Public, publicDns); err != nil {
-			return fmt.Errorf("Couldn't create public dns entry: %s %s - %v", e.Public, publicDns, err)
+	var records []Record
+	for _, r := range existing {
+		for _, name := range names {
+			if r.Name == name {
+				records = append(records, Record{Name: r.Name, Type: r.Type, Content: r.Content, TTL: r.TTL, Proxied: r.Proxied})
+				break
+			}
 		}
 	}
-
-	if err := cli.createRecord(ctx, zone.ID, e.CatchallPrivate, privateDns); err != nil {
-		return fmt.Errorf("Couldn't create private catchall dns entry: %s %s - %v", e.CatchallPrivate, privateDns, err)
-	}
-	if err := cli.createRecord(ctx, zone.ID, e.Private, privateDns); err != nil {
-		return fmt.Errorf("Couldn't create private dns entry: %s %s - %v", e.Private, privateDns, err)
-	}
-	if err := cli.createRecord(ctx, zone.ID, e.Fleet, instances[0].PublicDNSName); err != nil {
-		return fmt.Errorf("Couldn't create fleet dns entry: %s %s - %v", e.Fleet, instances[0].PublicDNSName, err)
-	}
-	return nil
+	return records, nil
 }
 
-// deleteEntries deletes DNS entries, given a stack name, and list of Entries to delete.
-func (cli *CloudFlareDNS) deleteEntries(name string, e *Entries) error {
+// apply makes the changes to the records in the zone.
+func (cli *CloudFlareDNS) apply(zone string, changes []Change) error {
 	ctx := context.TODO()
 
-	client := cli.client()
-
-	// First we need to find our zone
-	zone, err := cli.findZone(ctx, e.Zone)
+	zoneID, existing, err := cli.listRecords(ctx, zone)
 	if err != nil {
 		return errgo.Mask(err, errgo.Any)
 	}
 
-	records, err := client.Records.List(ctx, zone.ID)
-	if err != nil {
-		return errgo.Mask(err, errgo.Any)
-	}
-
-	for _, record := range records {
-		entriesRecord := record.Name == e.Catchall ||
-			record.Name == e.CatchallPrivate ||
-			record.Name == e.Public ||
-			record.Name == e.Private ||
-			record.Name == e.Fleet
-		if entriesRecord {
-			if err := client.Records.Delete(ctx, zone.ID, record.ID); err != nil {
-				return errgo.Mask(err, errgo.Any)
-			}
+	for _, change := range changes {
+		if err := applyChange(ctx, cli.client().Records, zoneID, existing, change); err != nil {
+			return fmt.Errorf("Couldn't %s %s dns entry: %s %s - %v", change.Action, change.Entry, change.Record.Name, change.Record.Content, err)
 		}
 	}
 	return nil
 }
 
-// update updates DNS records, given a swarm name, CNAME, dns content, and Entries.
-func (cli *CloudFlareDNS) update(swarmName, cname, dns string, e *Entries) error {
-	ctx := context.TODO()
-	client := cli.client()
-
-	zone, err := cli.findZone(ctx, e.Zone)
+// listRecords returns the ID and all records of the zone.
+func (cli *CloudFlareDNS) listRecords(ctx context.Context, zone string) (string, []*cloudflare.Record, error) {
+	z, err := cli.findZone(ctx, zone)
 	if err != nil {
-		return errgo.Mask(err, errgo.Any)
+		return "", nil, errgo.Mask(err, errgo.Any)
 	}
 
-	client.Records.Patch(ctx, &cloudflare.Record{
-		ZoneID:  zone.ID,
-		Type:    "CNAME",
-		Name:    cname,
-		Content: dns,
-	})
-
-	return nil
+	records, err := cli.client().Records.List(ctx, z.ID)
+	if err != nil {
+		return "", nil, errgo.Mask(err, errgo.Any)
+	}
+	return z.ID, records, nil
 }
 
 func (api *CloudFlareDNS) client() *cloudflare.Client {
@@ -149,27 +135,94 @@ func (api *CloudFlareDNS) client() *cloudflare.Client {
 	return api._client
 }
 
-func (cli *CloudFlareDNS) createRecord(ctx context.Context, zoneID, cname, dns string) error {
-	err := cli.client().Records.Create(ctx, &cloudflare.Record{
+// recordOptions returns the record options configured for the type of entry.
+func (cli *CloudFlareDNS) recordOptions(entryType string) RecordOptions {
+	options := cli.Records[entryType]
+	if options.TTL == 0 {
+		options.TTL = automaticTTL
+	}
+	return options
+}
+
+// applyChange makes the change to the existing records of the zone. A record
+// to create that exists already is updated, and a record to update that
+// doesn't exist is created, so changes can be applied again after a failure.
+func applyChange(ctx context.Context, records cloudFlareRecords, zoneID string, existing []*cloudflare.Record, change Change) error {
+	record := &cloudflare.Record{
 		ZoneID:  zoneID,
-		Type:    "CNAME",
-		Name:    cname,
-		Content: dns,
-	})
-	return errgo.Mask(err, errgo.Any)
+		Type:    change.Record.Type,
+		Name:    change.Record.Name,
+		Content: change.Record.Content,
+		TTL:     change.Record.TTL,
+		Proxied: change.Record.Proxied,
+	}
+
+	switch change.Action {
+	case DeleteAction:
+		if r := findRecord(existing, change.Record); r != nil {
+			return errgo.Mask(records.Delete(ctx, zoneID, r.ID), errgo.Any)
+		}
+		return nil
+	case UpdateAction:
+		if change.Current != nil {
+			if r := findRecord(existing, *change.Current); r != nil {
+				record.ID = r.ID
+				return errgo.Mask(records.Patch(ctx, record), errgo.Any)
+			}
+		}
+	}
+
+	if r := findRecord(existing, change.Record); r != nil {
+		if r.TTL == record.TTL && r.Proxied == record.Proxied {
+			return nil
+		}
+		record.ID = r.ID
+		return errgo.Mask(records.Patch(ctx, record), errgo.Any)
+	}
+	return errgo.Mask(records.Create(ctx, record), errgo.Any)
 }
 
+// findRecord returns the existing record matching the given one, or nil if there is none.
+func findRecord(existing []*cloudflare.Record, record Record) *cloudflare.Record {
+	for _, r := range existing {
+		if r.Name == record.Name && r.Type == record.Type && r.Content == record.Content {
+			return r
+		}
+	}
+	return nil
+}
+
+// findZone returns the zone of the given domain. Zones are listed once and
+// cached, as the zones of an account rarely change.
 func (cli *CloudFlareDNS) findZone(ctx context.Context, domain string) (*cloudflare.Zone, error) {
 	client := cli.client()
-	zones, err := client.Zones.List(ctx)
-	if err != nil {
-		return nil, errgo.Mask(err, errgo.Any)
-	}
 
-	for _, z := range zones {
-		if z.Name == domain {
-			return z, nil
+	cli.zonesMutex.Lock()
+	defer cli.zonesMutex.Unlock()
+
+	if cli.zones == nil {
+		zones, err := client.Zones.List(ctx)
+		if err != nil {
+			return nil, errgo.Mask(err, errgo.Any)
+		}
+
+		cli.zones = map[string]*cloudflare.Zone{}
+		for _, z := range zones {
+			cli.zones[z.Name] = z
 		}
 	}
+
+	if zone, ok := cli.zones[domain]; ok {
+		return zone, nil
+	}
 	return nil, errgo.Newf("no zone for domain %s found", domain)
 }
+
+func isEntryType(entryType string) bool {
+	for _, t := range EntryTypes {
+		if t == entryType {
+			return true
+		}
+	}
+	return false
+}
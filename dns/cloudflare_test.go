@@ -0,0 +1,77 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/crackcomm/cloudflare"
+	"golang.org/x/net/context"
+)
+
+// fakeRecords records the changes made to the records of a zone.
+type fakeRecords struct {
+	created, patched []*cloudflare.Record
+	deleted          []string
+}
+
+func (r *fakeRecords) Create(ctx context.Context, record *cloudflare.Record) error {
+	r.created = append(r.created, record)
+	return nil
+}
+
+func (r *fakeRecords) Patch(ctx context.Context, record *cloudflare.Record) error {
+	r.patched = append(r.patched, record)
+	return nil
+}
+
+func (r *fakeRecords) Delete(ctx context.Context, zoneID, recordID string) error {
+	r.deleted = append(r.deleted, recordID)
+	return nil
+}
+
+func TestApplyChange(t *testing.T) {
+	existing := []*cloudflare.Record{
+		{ID: "1", Type: "CNAME", Name: "demo.example.com", Content: "elb-1.amazonaws.com", TTL: automaticTTL},
+		{ID: "2", Type: "CNAME", Name: "demo.fleet.example.com", Content: "ec2-1.amazonaws.com", TTL: automaticTTL},
+	}
+	cname := func(name, content string) Record {
+		return Record{Name: name, Type: "CNAME", Content: content, TTL: automaticTTL}
+	}
+	proxied := cname("demo.example.com", "elb-1.amazonaws.com")
+	proxied.Proxied = true
+
+	tests := []struct {
+		change                    Change
+		created, patched, deleted int
+	}{
+		// record to create exists already
+		{Change{Action: CreateAction, Record: cname("demo.example.com", "elb-1.amazonaws.com")}, 0, 0, 0},
+		// record to create exists with other options
+		{Change{Action: CreateAction, Record: proxied}, 0, 1, 0},
+		// missing record
+		{Change{Action: CreateAction, Record: cname("*.demo.example.com", "elb-1.amazonaws.com")}, 1, 0, 0},
+		// changed content
+		{Change{Action: UpdateAction, Record: cname("demo.fleet.example.com", "ec2-2.amazonaws.com"), Current: &Record{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-1.amazonaws.com"}}, 0, 1, 0},
+		// record to update is missing
+		{Change{Action: UpdateAction, Record: cname("demo.private.example.com", "elb-2.amazonaws.com"), Current: &Record{Name: "demo.private.example.com", Type: "CNAME", Content: "elb-3.amazonaws.com"}}, 1, 0, 0},
+		// record to delete
+		{Change{Action: DeleteAction, Record: cname("demo.fleet.example.com", "ec2-1.amazonaws.com")}, 0, 0, 1},
+		// record to delete is gone already
+		{Change{Action: DeleteAction, Record: cname("demo.fleet.example.com", "ec2-2.amazonaws.com")}, 0, 0, 0},
+	}
+
+	for i, test := range tests {
+		records := &fakeRecords{}
+		if err := applyChange(context.TODO(), records, "zone", existing, test.change); err != nil {
+			t.Fatalf("%d: failed to apply change: %v", i, err)
+		}
+
+		if len(records.created) != test.created || len(records.patched) != test.patched || len(records.deleted) != test.deleted {
+			t.Errorf("%d: expected %d created, %d patched and %d deleted records, got %#v", i, test.created, test.patched, test.deleted, records)
+		}
+		for _, record := range records.patched {
+			if record.ID == "" {
+				t.Errorf("%d: expected existing record to be patched, got %#v", i, record)
+			}
+		}
+	}
+}
@@ -23,8 +23,18 @@ type NamingPattern struct {
 	Public          string
 	Private         string
 	Fleet           string
+
+	// The kind of records of the fleet entry, see FleetCNAME
+	FleetRecords string
 }
 
+// Kinds of records of the fleet entry.
+const (
+	FleetCNAME     = "cname"      // A CNAME record to the public DNS name of one instance.
+	FleetPublicIP  = "public-ip"  // An A record with the public IP of each healthy instance.
+	FleetPrivateIP = "private-ip" // An A record with the private IP of each healthy instance.
+)
+
 var (
 	DefaultNamingPattern = NamingPattern{
 		Zone:            "example.com",
@@ -33,9 +43,22 @@ var (
 		Public:          "{{.Stack}}",
 		Private:         "{{.Stack}}.private",
 		Fleet:           "{{.Stack}}.fleet",
+		FleetRecords:    FleetCNAME,
 	}
 )
 
+// Types of entries, as used to configure the records of each entry.
+const (
+	CatchallEntry        = "catchall"
+	CatchallPrivateEntry = "catchall-private"
+	PublicEntry          = "public"
+	PrivateEntry         = "private"
+	FleetEntry           = "fleet"
+)
+
+// EntryTypes are all types of entries.
+var EntryTypes = []string{CatchallEntry, CatchallPrivateEntry, PublicEntry, PrivateEntry, FleetEntry}
+
 // GetEntries returns Entries, given a stack name.
 func (np NamingPattern) GetEntries(stackName string) *Entries {
 	return &Entries{
@@ -46,6 +69,8 @@ func (np NamingPattern) GetEntries(stackName string) *Entries {
 		Public:          np.mustParse(np.Public, stackName),
 		Private:         np.mustParse(np.Private, stackName),
 		Fleet:           np.mustParse(np.Fleet, stackName),
+
+		FleetRecords: np.FleetRecords,
 	}
 }
 
@@ -60,45 +85,83 @@ type Entries struct {
 	Public          string
 	Private         string
 	Fleet           string
+
+	// The kind of records of the fleet entry
+	FleetRecords string
+}
+
+// Names returns the names of the entries of a swarm of the given type. Primary
+// swarms have no public entries. An empty type returns all entries.
+func (e *Entries) Names(swarmType string) []string {
+	if swarmType == "primary" {
+		return []string{e.CatchallPrivate, e.Private, e.Fleet}
+	}
+	return []string{e.Catchall, e.CatchallPrivate, e.Public, e.Private, e.Fleet}
+}
+
+// Type returns the type of the entry with the given name, or an empty string
+// if there is no such entry.
+func (e *Entries) Type(name string) string {
+	switch name {
+	case e.Catchall:
+		return CatchallEntry
+	case e.CatchallPrivate:
+		return CatchallPrivateEntry
+	case e.Public:
+		return PublicEntry
+	case e.Private:
+		return PrivateEntry
+	case e.Fleet:
+		return FleetEntry
+	}
+	return ""
 }
 
 // DNSService provides mangement of a swarm's DNS entries.
 type DNSService interface {
-	createSwarmEntries(s *swarm.Swarm, entries *Entries) error
-	deleteEntries(name string, entries *Entries) error
-	update(stackName, cname, dns string, entries *Entries) error
-}
+	// managesRecords returns whether the service manages DNS records at
+	// all. Nothing is planned for services that don't.
+	managesRecords() bool
 
-// CreateSwarmEntries creates DNS entries, given a NamingPattern and Swarm.
-func CreateSwarmEntries(service DNSService, pattern NamingPattern, s *swarm.Swarm) error {
-	return service.createSwarmEntries(s, pattern.GetEntries(s.Name))
+	// records returns the records with the given names in the zone.
+	records(zone string, names []string) ([]Record, error)
+
+	// recordOptions returns the options the records of the type of entry
+	// should have.
+	recordOptions(entryType string) RecordOptions
+
+	// apply makes the changes to the records in the zone.
+	apply(zone string, changes []Change) error
 }
 
-// DeleteEntries deletes DNS entries, given a NamingPattern and stack name.
-func DeleteEntries(service DNSService, pattern NamingPattern, stackName string) error {
-	return service.deleteEntries(stackName, pattern.GetEntries(stackName))
+// CreateSwarmEntries creates DNS entries, given a NamingPattern and Swarm, and
+// returns the changes made. Existing records of the entries are updated.
+func CreateSwarmEntries(service DNSService, pattern NamingPattern, s *swarm.Swarm) ([]Change, error) {
+	instances, err := s.GetInstances()
+	if err != nil {
+		return nil, err
+	}
+	return Update(service, pattern, s, instances)
 }
 
-// Update ensures that necessary DNS records that might have changed are updated.
-//
-// NOTE: This currently only checks the fleet entry.
-// Returns false if no update was necessary.
-func Update(service DNSService, pattern NamingPattern, s *swarm.Swarm, instances []swarmtypes.Instance) (bool, error) {
-	dnsEntries := pattern.GetEntries(s.Name)
-	dnsChanged := false
-
-	for _, inst := range instances {
-		if inst.PublicDNSName == "" {
-			continue
-		}
-
-		if err := service.update(s.Name, dnsEntries.Fleet, inst.PublicDNSName, dnsEntries); err != nil {
-			return true, err
-		}
-		dnsChanged = true
+// DeleteEntries deletes DNS entries, given a NamingPattern and stack name, and
+// returns the changes made.
+func DeleteEntries(service DNSService, pattern NamingPattern, stackName string) ([]Change, error) {
+	changes, err := PlanDelete(service, pattern, stackName)
+	if err != nil {
+		return nil, err
 	}
+	return changes, Apply(service, pattern, changes)
+}
 
-	return dnsChanged, nil
+// Update reconciles the DNS records of the swarm with its current load
+// balancers and the given running instances, and returns the changes made.
+func Update(service DNSService, pattern NamingPattern, s *swarm.Swarm, instances []swarmtypes.Instance) ([]Change, error) {
+	changes, err := PlanUpdate(service, pattern, s, instances)
+	if err != nil {
+		return nil, err
+	}
+	return changes, Apply(service, pattern, changes)
 }
 
 func (np NamingPattern) parse(templateText, stackName string) (string, error) {
@@ -1,9 +1,5 @@
 package dns
 
-import (
-	"github.com/giantswarm/kocho/swarm"
-)
-
 // NoopDNS provides a DNSService implementation that does nothing.
 // Useful for when we don't want to set up DNS at all.
 type NoopDNS struct{}
@@ -13,17 +9,22 @@ func NewNoopDNS() *NoopDNS {
 	return &NoopDNS{}
 }
 
-// createSwarmEntries does nothing, returning immediately.
-func (ndns *NoopDNS) createSwarmEntries(s *swarm.Swarm, entries *Entries) error {
-	return nil
+// managesRecords returns false, so no changes are planned.
+func (ndns *NoopDNS) managesRecords() bool {
+	return false
 }
 
-// deleteEntries does nothing, returning immediately.
-func (ndns *NoopDNS) deleteEntries(name string, entries *Entries) error {
-	return nil
+// records returns no records, as it never creates any.
+func (ndns *NoopDNS) records(zone string, names []string) ([]Record, error) {
+	return nil, nil
+}
+
+// recordOptions returns no options, as it never creates records.
+func (ndns *NoopDNS) recordOptions(entryType string) RecordOptions {
+	return RecordOptions{}
 }
 
-// update does nothing, returning immediately.
-func (ndns *NoopDNS) update(stackName, cname, dns string, entries *Entries) error {
+// apply does nothing, returning immediately.
+func (ndns *NoopDNS) apply(zone string, changes []Change) error {
 	return nil
 }
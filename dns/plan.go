@@ -0,0 +1,258 @@
+package dns
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/swarm"
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+// Actions of changes.
+const (
+	CreateAction = "create"
+	UpdateAction = "update"
+	DeleteAction = "delete"
+)
+
+// Record is a DNS record of an entry.
+type Record struct {
+	Name    string
+	Type    string
+	Content string
+
+	TTL     int  // TTL is the TTL of the record in seconds.
+	Proxied bool // Proxied is whether traffic goes through the DNS backend.
+}
+
+// Change is a change of a DNS record, planned by comparing the records a swarm
+// should have with the ones the DNS backend has.
+type Change struct {
+	Action string // Action is either create, update or delete.
+	Entry  string // Entry is the type of the entry of the record, e.g. fleet.
+
+	// Record is the record to create, the updated record, or the record to delete.
+	Record Record
+
+	// Current is the record before an update.
+	Current *Record
+}
+
+// Names returns the names of the records changed, once each.
+func Names(changes []Change) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, change := range changes {
+		if !seen[change.Record.Name] {
+			seen[change.Record.Name] = true
+			names = append(names, change.Record.Name)
+		}
+	}
+	return names
+}
+
+// PlanUpdate returns the changes making the DNS records of the swarm point to
+// its current load balancers and the given running instances. No changes are
+// planned if the service doesn't manage records.
+func PlanUpdate(service DNSService, pattern NamingPattern, s *swarm.Swarm, instances []swarmtypes.Instance) ([]Change, error) {
+	if !service.managesRecords() {
+		return nil, nil
+	}
+	entries := pattern.GetEntries(s.Name)
+
+	current, err := service.records(entries.Zone, entries.Names(""))
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := swarmEndpoints{instances: instances}
+	if endpoints.privateDNS, err = s.GetPrivateDNS(); err != nil {
+		return nil, err
+	}
+	if s.Type != "primary" {
+		if endpoints.publicDNS, err = s.GetPublicDNS(); err != nil {
+			return nil, err
+		}
+	}
+
+	desired, err := desiredRecords(entries, s.Type, endpoints, current)
+	if err != nil {
+		return nil, err
+	}
+	for i := range desired {
+		options := service.recordOptions(entries.Type(desired[i].Name))
+		desired[i].TTL, desired[i].Proxied = options.TTL, options.Proxied
+	}
+	return diffRecords(entries, current, desired), nil
+}
+
+// PlanDelete returns the changes deleting all DNS records of the named swarm.
+// No changes are planned if the service doesn't manage records.
+func PlanDelete(service DNSService, pattern NamingPattern, stackName string) ([]Change, error) {
+	if !service.managesRecords() {
+		return nil, nil
+	}
+	entries := pattern.GetEntries(stackName)
+
+	current, err := service.records(entries.Zone, entries.Names(""))
+	if err != nil {
+		return nil, err
+	}
+	return diffRecords(entries, current, nil), nil
+}
+
+// Apply makes the changes to the DNS records in the zone of the pattern.
+func Apply(service DNSService, pattern NamingPattern, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return service.apply(pattern.Zone, changes)
+}
+
+// swarmEndpoints are the endpoints of a swarm the DNS records point to.
+type swarmEndpoints struct {
+	publicDNS  string
+	privateDNS string
+	instances  []swarmtypes.Instance
+}
+
+// desiredRecords returns the records the entries of a swarm of the given type
+// should have. Primary swarms have no public entries. A fleet CNAME record
+// keeps pointing to the same instance as long as it is healthy. Without a
+// healthy instance to point to, the current fleet records are kept.
+func desiredRecords(e *Entries, swarmType string, endpoints swarmEndpoints, current []Record) ([]Record, error) {
+	var records []Record
+	cname := func(name, content string) {
+		if content != "" {
+			records = append(records, Record{Name: name, Type: "CNAME", Content: content})
+		}
+	}
+
+	if swarmType != "primary" {
+		cname(e.Catchall, endpoints.publicDNS)
+		cname(e.Public, endpoints.publicDNS)
+	}
+	cname(e.CatchallPrivate, endpoints.privateDNS)
+	cname(e.Private, endpoints.privateDNS)
+
+	switch e.FleetRecords {
+	case "", FleetCNAME:
+		if target := fleetTarget(e.Fleet, endpoints.instances, current); target != "" {
+			cname(e.Fleet, target)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: no healthy instance with a public DNS name, keeping the fleet entry %s\n", e.Fleet)
+			records = append(records, recordsOf(current, e.Fleet)...)
+		}
+	case FleetPublicIP, FleetPrivateIP:
+		addresses := fleetAddresses(e.FleetRecords, endpoints.instances)
+		if len(addresses) == 0 {
+			fmt.Fprintf(os.Stderr, "Warning: no healthy instance with an IP, keeping the fleet entry %s\n", e.Fleet)
+			records = append(records, recordsOf(current, e.Fleet)...)
+		}
+		for _, address := range addresses {
+			records = append(records, Record{Name: e.Fleet, Type: "A", Content: address})
+		}
+	default:
+		return nil, errgo.Newf("invalid kind of fleet dns records: %s", e.FleetRecords)
+	}
+
+	return records, nil
+}
+
+// fleetTarget returns the public DNS name of the healthy instance the fleet
+// entry should point to, or an empty string if there is none. The current
+// target is kept if it is one of them.
+func fleetTarget(name string, instances []swarmtypes.Instance, current []Record) string {
+	var first string
+	for _, instance := range instances {
+		if instance.PublicDNSName == "" || !instance.Healthy() {
+			continue
+		}
+		if first == "" {
+			first = instance.PublicDNSName
+		}
+
+		for _, record := range current {
+			if record.Name == name && record.Type == "CNAME" && record.Content == instance.PublicDNSName {
+				return record.Content
+			}
+		}
+	}
+	return first
+}
+
+// fleetAddresses returns the public or private IPs of the healthy instances,
+// depending on the kind of fleet records.
+func fleetAddresses(kind string, instances []swarmtypes.Instance) []string {
+	var addresses []string
+	seen := map[string]bool{}
+	for _, instance := range instances {
+		address := instance.PublicIPAddress
+		if kind == FleetPrivateIP {
+			address = instance.PrivateIPAddress
+		}
+
+		if address != "" && instance.Healthy() && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// diffRecords returns the changes turning the current records of the entries
+// into the desired ones. A single record of a name is updated in place, other
+// records are deleted before the records of their name are created. Records
+// only differing in their options are updated.
+func diffRecords(e *Entries, current, desired []Record) []Change {
+	var changes []Change
+	for _, name := range e.Names("") {
+		entry := e.Type(name)
+		have := recordsOf(current, name)
+		want := recordsOf(desired, name)
+
+		if len(have) == 1 && len(want) == 1 && have[0].Type == want[0].Type {
+			if have[0] != want[0] {
+				changes = append(changes, Change{Action: UpdateAction, Entry: entry, Record: want[0], Current: &have[0]})
+			}
+			continue
+		}
+
+		for _, record := range have {
+			if matchingRecord(want, record) == nil {
+				changes = append(changes, Change{Action: DeleteAction, Entry: entry, Record: record})
+			}
+		}
+		for _, record := range want {
+			if current := matchingRecord(have, record); current == nil {
+				changes = append(changes, Change{Action: CreateAction, Entry: entry, Record: record})
+			} else if *current != record {
+				changes = append(changes, Change{Action: UpdateAction, Entry: entry, Record: record, Current: current})
+			}
+		}
+	}
+	return changes
+}
+
+func recordsOf(records []Record, name string) []Record {
+	var result []Record
+	for _, record := range records {
+		if record.Name == name {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+// matchingRecord returns the record with the same name, type and content as
+// the given one, or nil if there is none.
+func matchingRecord(records []Record, record Record) *Record {
+	for i, r := range records {
+		if r.Name == record.Name && r.Type == record.Type && r.Content == record.Content {
+			return &records[i]
+		}
+	}
+	return nil
+}
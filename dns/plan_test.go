@@ -0,0 +1,221 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/kocho/swarm"
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+func TestDesiredRecords(t *testing.T) {
+	entries := DefaultNamingPattern.GetEntries("demo")
+	endpoints := swarmEndpoints{
+		publicDNS:  "public-elb.amazonaws.com",
+		privateDNS: "private-elb.amazonaws.com",
+		instances: []swarmtypes.Instance{
+			{Id: "i-1", PublicDNSName: "ec2-1.amazonaws.com"},
+			{Id: "i-2", PublicDNSName: "ec2-2.amazonaws.com"},
+		},
+	}
+
+	records, err := desiredRecords(entries, "secondary", endpoints, nil)
+	if err != nil {
+		t.Fatalf("Failed to compute records: %v", err)
+	}
+	expected := []Record{
+		{Name: "*.demo.example.com", Type: "CNAME", Content: "public-elb.amazonaws.com"},
+		{Name: "demo.example.com", Type: "CNAME", Content: "public-elb.amazonaws.com"},
+		{Name: "*.demo.private.example.com", Type: "CNAME", Content: "private-elb.amazonaws.com"},
+		{Name: "demo.private.example.com", Type: "CNAME", Content: "private-elb.amazonaws.com"},
+		{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-1.amazonaws.com"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("expected records %#v, got %#v", expected, records)
+	}
+
+	// Primary swarms have no public entries, and the fleet entry keeps its instance
+	current := []Record{{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-2.amazonaws.com"}}
+	records, err = desiredRecords(entries, "primary", endpoints, current)
+	if err != nil {
+		t.Fatalf("Failed to compute records: %v", err)
+	}
+	expected = []Record{
+		{Name: "*.demo.private.example.com", Type: "CNAME", Content: "private-elb.amazonaws.com"},
+		{Name: "demo.private.example.com", Type: "CNAME", Content: "private-elb.amazonaws.com"},
+		{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-2.amazonaws.com"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("expected records %#v, got %#v", expected, records)
+	}
+}
+
+func TestDesiredFleetRecords(t *testing.T) {
+	entries := DefaultNamingPattern.GetEntries("demo")
+	endpoints := swarmEndpoints{
+		privateDNS: "private-elb.amazonaws.com",
+		instances: []swarmtypes.Instance{
+			{Id: "i-1", PublicDNSName: "ec2-1.amazonaws.com", PublicIPAddress: "52.1.1.1", PrivateIPAddress: "10.0.0.1", State: "running", LifecycleState: "Terminating"},
+			{Id: "i-2", PublicDNSName: "ec2-2.amazonaws.com", PublicIPAddress: "52.1.1.2", PrivateIPAddress: "10.0.0.2", State: "running"},
+			{Id: "i-3", PublicDNSName: "ec2-3.amazonaws.com", PublicIPAddress: "52.1.1.3", PrivateIPAddress: "10.0.0.3", State: "running"},
+		},
+	}
+
+	tests := []struct {
+		kind     string
+		expected []Record
+	}{
+		{FleetCNAME, []Record{{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-2.amazonaws.com"}}},
+		{FleetPublicIP, []Record{{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.2"}, {Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.3"}}},
+		{FleetPrivateIP, []Record{{Name: "demo.fleet.example.com", Type: "A", Content: "10.0.0.2"}, {Name: "demo.fleet.example.com", Type: "A", Content: "10.0.0.3"}}},
+	}
+
+	for _, test := range tests {
+		entries.FleetRecords = test.kind
+		records, err := desiredRecords(entries, "primary", endpoints, nil)
+		if err != nil {
+			t.Fatalf("%s: failed to compute records: %v", test.kind, err)
+		}
+		if fleet := recordsOf(records, entries.Fleet); !reflect.DeepEqual(fleet, test.expected) {
+			t.Errorf("%s: expected fleet records %#v, got %#v", test.kind, test.expected, fleet)
+		}
+	}
+
+	entries.FleetRecords = "mx"
+	if _, err := desiredRecords(entries, "primary", endpoints, nil); err == nil {
+		t.Errorf("expected invalid kind of fleet records to fail")
+	}
+}
+
+func TestDesiredFleetRecordsWithoutInstances(t *testing.T) {
+	entries := DefaultNamingPattern.GetEntries("demo")
+	endpoints := swarmEndpoints{
+		privateDNS: "private-elb.amazonaws.com",
+		instances:  []swarmtypes.Instance{{Id: "i-1", State: "running"}},
+	}
+
+	current := []Record{{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-1.amazonaws.com"}}
+	records, err := desiredRecords(entries, "primary", endpoints, current)
+	if err != nil {
+		t.Fatalf("Failed to compute records: %v", err)
+	}
+	expected := []Record{
+		{Name: "*.demo.private.example.com", Type: "CNAME", Content: "private-elb.amazonaws.com"},
+		{Name: "demo.private.example.com", Type: "CNAME", Content: "private-elb.amazonaws.com"},
+		{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-1.amazonaws.com"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("expected records %#v, got %#v", expected, records)
+	}
+}
+
+func TestDesiredFleetAddressesWithoutInstances(t *testing.T) {
+	entries := DefaultNamingPattern.GetEntries("demo")
+	endpoints := swarmEndpoints{
+		privateDNS: "private-elb.amazonaws.com",
+		instances:  []swarmtypes.Instance{{Id: "i-1", PublicIPAddress: "52.1.1.1", State: "running", LifecycleState: "Terminating"}},
+	}
+	current := []Record{
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.1"},
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.2"},
+	}
+
+	for _, kind := range []string{FleetPublicIP, FleetPrivateIP} {
+		entries.FleetRecords = kind
+		records, err := desiredRecords(entries, "primary", endpoints, current)
+		if err != nil {
+			t.Fatalf("%s: failed to compute records: %v", kind, err)
+		}
+		if fleet := recordsOf(records, entries.Fleet); !reflect.DeepEqual(fleet, current) {
+			t.Errorf("%s: expected fleet records %#v, got %#v", kind, current, fleet)
+		}
+	}
+}
+
+func TestPlanWithoutManagedRecords(t *testing.T) {
+	service := NewNoopDNS()
+	s := &swarm.Swarm{Name: "demo", Type: "primary"}
+
+	if changes, err := PlanUpdate(service, DefaultNamingPattern, s, nil); err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes to update, got %#v %v", changes, err)
+	}
+	if changes, err := PlanDelete(service, DefaultNamingPattern, "demo"); err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes to delete, got %#v %v", changes, err)
+	}
+}
+
+func TestDiffFleetRecords(t *testing.T) {
+	entries := DefaultNamingPattern.GetEntries("demo")
+	current := []Record{
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.1"},
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.2"},
+	}
+	desired := []Record{
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.2"},
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.3"},
+	}
+
+	changes := diffRecords(entries, current, desired)
+	expected := []Change{
+		{Action: DeleteAction, Entry: FleetEntry, Record: current[0]},
+		{Action: CreateAction, Entry: FleetEntry, Record: desired[1]},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected changes %#v, got %#v", expected, changes)
+	}
+}
+
+func TestDiffRecords(t *testing.T) {
+	entries := DefaultNamingPattern.GetEntries("demo")
+	current := []Record{
+		{Name: "demo.example.com", Type: "CNAME", Content: "public-elb.amazonaws.com"},
+		{Name: "demo.private.example.com", Type: "A", Content: "10.0.0.1"},
+		{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-1.amazonaws.com"},
+		{Name: "*.demo.example.com", Type: "CNAME", Content: "public-elb.amazonaws.com"},
+	}
+	desired := []Record{
+		{Name: "demo.example.com", Type: "CNAME", Content: "public-elb.amazonaws.com"},
+		{Name: "demo.private.example.com", Type: "CNAME", Content: "private-elb.amazonaws.com"},
+		{Name: "demo.fleet.example.com", Type: "CNAME", Content: "ec2-2.amazonaws.com"},
+		{Name: "*.demo.private.example.com", Type: "CNAME", Content: "private-elb.amazonaws.com"},
+	}
+
+	changes := diffRecords(entries, current, desired)
+	expected := []Change{
+		{Action: DeleteAction, Entry: CatchallEntry, Record: current[3]},
+		{Action: CreateAction, Entry: CatchallPrivateEntry, Record: desired[3]},
+		{Action: DeleteAction, Entry: PrivateEntry, Record: current[1]},
+		{Action: CreateAction, Entry: PrivateEntry, Record: desired[1]},
+		{Action: UpdateAction, Entry: FleetEntry, Record: desired[2], Current: &current[2]},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected changes %#v, got %#v", expected, changes)
+	}
+
+	if changes := diffRecords(entries, desired, desired); len(changes) != 0 {
+		t.Fatalf("expected no changes of records up to date, got %#v", changes)
+	}
+}
+
+func TestDiffRecordOptions(t *testing.T) {
+	entries := DefaultNamingPattern.GetEntries("demo")
+	current := []Record{
+		{Name: "demo.example.com", Type: "CNAME", Content: "public-elb.amazonaws.com", TTL: automaticTTL},
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.1", TTL: automaticTTL},
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.2", TTL: 60},
+	}
+	desired := []Record{
+		{Name: "demo.example.com", Type: "CNAME", Content: "public-elb.amazonaws.com", TTL: automaticTTL, Proxied: true},
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.1", TTL: 60},
+		{Name: "demo.fleet.example.com", Type: "A", Content: "52.1.1.2", TTL: 60},
+	}
+
+	changes := diffRecords(entries, current, desired)
+	expected := []Change{
+		{Action: UpdateAction, Entry: PublicEntry, Record: desired[0], Current: &current[0]},
+		{Action: UpdateAction, Entry: FleetEntry, Record: desired[1], Current: &current[1]},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected changes %#v, got %#v", expected, changes)
+	}
+}
@@ -0,0 +1,144 @@
+package lock
+
+import (
+	"time"
+
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/provider"
+)
+
+const (
+	// dynamoDBTimeLayout formats times so they compare as strings.
+	dynamoDBTimeLayout = "2006-01-02T15:04:05Z"
+
+	// createCondition allows to store a lock if the swarm has none, or an expired one.
+	createCondition = "attribute_not_exists(#swarm) OR #expires <= :now"
+
+	// tokenCondition allows to renew or remove a lock only if it has the given token.
+	tokenCondition = "#token = :token"
+)
+
+// ItemStore stores items of string attributes in tables, e.g. in DynamoDB.
+type ItemStore interface {
+	// GetItem returns the item with the given key, or provider.ErrNotFound
+	// if there is no such item.
+	GetItem(table string, key map[string]string) (map[string]string, error)
+
+	// PutItem stores the item if the condition holds, and returns
+	// provider.ErrConditionFailed otherwise. An empty condition always holds.
+	PutItem(table string, item map[string]string, condition string, names, values map[string]string) error
+
+	// DeleteItem removes the item with the given key if the condition
+	// holds, and returns provider.ErrConditionFailed otherwise.
+	DeleteItem(table string, key map[string]string, condition string, names, values map[string]string) error
+}
+
+// NewDynamoDBBackend returns a DynamoDBBackend keeping the locks in the given table.
+func NewDynamoDBBackend(store ItemStore, table string) *DynamoDBBackend {
+	return &DynamoDBBackend{
+		store: store,
+		table: table,
+	}
+}
+
+// DynamoDBBackend keeps locks in a DynamoDB table with the string hash key
+// swarm, one item per swarm. Locks are created with conditional writes, so
+// they are shared by everyone using the table.
+type DynamoDBBackend struct {
+	store ItemStore
+	table string
+}
+
+// Create stores the lock in the item of the swarm, unless it holds a lock not expired yet.
+func (b *DynamoDBBackend) Create(lock Info, now time.Time) error {
+	err := b.store.PutItem(b.table, encodeItem(lock), createCondition,
+		map[string]string{"#swarm": "swarm", "#expires": "expires"},
+		map[string]string{":now": now.UTC().Format(dynamoDBTimeLayout)})
+	if err != provider.ErrConditionFailed {
+		return errgo.Mask(err)
+	}
+
+	holder, err := b.Get(lock.Swarm)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if holder == nil {
+		return errgo.Newf("couldn't lock swarm %s, the lock was released meanwhile", lock.Swarm)
+	}
+	return &LockedError{Lock: *holder}
+}
+
+// Get returns the lock in the item of the swarm, or nil if there is no such item.
+func (b *DynamoDBBackend) Get(swarm string) (*Info, error) {
+	item, err := b.store.GetItem(b.table, map[string]string{"swarm": swarm})
+	if err == provider.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return decodeItem(item)
+}
+
+// Delete removes the item of the swarm if it holds the lock with the given token.
+func (b *DynamoDBBackend) Delete(swarm, token string) error {
+	key := map[string]string{"swarm": swarm}
+
+	var err error
+	if token == "" {
+		err = b.store.DeleteItem(b.table, key, "", nil, nil)
+	} else {
+		err = b.store.DeleteItem(b.table, key, tokenCondition,
+			map[string]string{"#token": "token"},
+			map[string]string{":token": token})
+	}
+	if err == provider.ErrConditionFailed {
+		// Someone else holds the lock by now
+		return nil
+	}
+	return errgo.Mask(err)
+}
+
+func encodeItem(lock Info) map[string]string {
+	return map[string]string{
+		"swarm":    lock.Swarm,
+		"token":    lock.Token,
+		"user":     lock.User,
+		"host":     lock.Host,
+		"command":  lock.Command,
+		"acquired": lock.Acquired.UTC().Format(dynamoDBTimeLayout),
+		"expires":  lock.Expires.UTC().Format(dynamoDBTimeLayout),
+	}
+}
+
+func decodeItem(item map[string]string) (*Info, error) {
+	acquired, err := time.Parse(dynamoDBTimeLayout, item["acquired"])
+	if err != nil {
+		return nil, errgo.Notef(err, "invalid lock of swarm %s", item["swarm"])
+	}
+	expires, err := time.Parse(dynamoDBTimeLayout, item["expires"])
+	if err != nil {
+		return nil, errgo.Notef(err, "invalid lock of swarm %s", item["swarm"])
+	}
+
+	return &Info{
+		Swarm:    item["swarm"],
+		Token:    item["token"],
+		User:     item["user"],
+		Host:     item["host"],
+		Command:  item["command"],
+		Acquired: acquired,
+		Expires:  expires,
+	}, nil
+}
+
+// Renew stores the lock in the item of the swarm if it holds the lock with the same token.
+func (b *DynamoDBBackend) Renew(lock Info) error {
+	err := b.store.PutItem(b.table, encodeItem(lock), tokenCondition,
+		map[string]string{"#token": "token"},
+		map[string]string{":token": lock.Token})
+	if err == provider.ErrConditionFailed {
+		return ErrLockLost
+	}
+	return errgo.Mask(err)
+}
@@ -0,0 +1,51 @@
+package lock
+
+import (
+	"testing"
+
+	"github.com/giantswarm/kocho/provider"
+)
+
+// fakeItemStore evaluates the conditions used by the DynamoDBBackend.
+type fakeItemStore map[string]map[string]string
+
+func (s fakeItemStore) GetItem(table string, key map[string]string) (map[string]string, error) {
+	item, ok := s[table+"/"+key["swarm"]]
+	if !ok {
+		return nil, provider.ErrNotFound
+	}
+	return item, nil
+}
+
+func (s fakeItemStore) PutItem(table string, item map[string]string, condition string, names, values map[string]string) error {
+	existing, ok := s[table+"/"+item["swarm"]]
+	if condition == createCondition && ok && existing["expires"] > values[":now"] {
+		return provider.ErrConditionFailed
+	}
+	if condition == tokenCondition && (!ok || existing["token"] != values[":token"]) {
+		return provider.ErrConditionFailed
+	}
+	s[table+"/"+item["swarm"]] = item
+	return nil
+}
+
+func (s fakeItemStore) DeleteItem(table string, key map[string]string, condition string, names, values map[string]string) error {
+	existing, ok := s[table+"/"+key["swarm"]]
+	if condition == tokenCondition && (!ok || existing["token"] != values[":token"]) {
+		return provider.ErrConditionFailed
+	}
+	delete(s, table+"/"+key["swarm"])
+	return nil
+}
+
+func TestDynamoDBBackendLocker(t *testing.T) {
+	testLocker(t, NewDynamoDBBackend(fakeItemStore{}, "kocho-locks"))
+}
+
+func TestDynamoDBBackendUnlock(t *testing.T) {
+	testUnlock(t, NewDynamoDBBackend(fakeItemStore{}, "kocho-locks"))
+}
+
+func TestDynamoDBBackendRenew(t *testing.T) {
+	testRenew(t, NewDynamoDBBackend(fakeItemStore{}, "kocho-locks"))
+}
@@ -0,0 +1,116 @@
+package lock
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// NewFileBackend returns a FileBackend keeping the locks in the given directory.
+func NewFileBackend(dir string) *FileBackend {
+	return &FileBackend{dir: dir}
+}
+
+// FileBackend keeps locks in a local directory, one JSON file per swarm. It
+// only keeps commands on the same host from changing a swarm at the same time.
+type FileBackend struct {
+	dir string
+}
+
+func (b *FileBackend) path(swarm string) string {
+	return filepath.Join(b.dir, swarm+".lock")
+}
+
+// Create stores the lock in the file of the swarm. An expired lock is taken over.
+func (b *FileBackend) Create(lock Info, now time.Time) error {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if err := os.MkdirAll(b.dir, 0700); err != nil {
+		return errgo.Mask(err)
+	}
+
+	// Creating the file exclusively fails if the swarm is locked already
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(b.path(lock.Swarm), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			if _, err := file.Write(data); err != nil {
+				file.Close()
+				return errgo.Mask(err)
+			}
+			return errgo.Mask(file.Close())
+		} else if !os.IsExist(err) {
+			return errgo.Mask(err)
+		}
+
+		holder, err := b.Get(lock.Swarm)
+		if err != nil {
+			return errgo.Mask(err)
+		}
+		if holder != nil {
+			if !holder.Expired(now) {
+				return &LockedError{Lock: *holder}
+			}
+			if err := b.Delete(lock.Swarm, holder.Token); err != nil {
+				return errgo.Mask(err)
+			}
+		}
+	}
+	return errgo.Newf("couldn't create lock file of swarm %s", lock.Swarm)
+}
+
+// Get returns the lock in the file of the swarm, or nil if there is no such file.
+func (b *FileBackend) Get(swarm string) (*Info, error) {
+	data, err := ioutil.ReadFile(b.path(swarm))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, errgo.Notef(err, "invalid lock file of swarm %s", swarm)
+	}
+	return &info, nil
+}
+
+// Delete removes the file of the swarm if it holds the lock with the given token.
+func (b *FileBackend) Delete(swarm, token string) error {
+	if token != "" {
+		holder, err := b.Get(swarm)
+		if err != nil {
+			return errgo.Mask(err)
+		}
+		if holder == nil || holder.Token != token {
+			return nil
+		}
+	}
+
+	if err := os.Remove(b.path(swarm)); err != nil && !os.IsNotExist(err) {
+		return errgo.Mask(err)
+	}
+	return nil
+}
+
+// Renew rewrites the file of the swarm if it holds the lock with the same token.
+func (b *FileBackend) Renew(lock Info) error {
+	holder, err := b.Get(lock.Swarm)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if holder == nil || holder.Token != lock.Token {
+		return ErrLockLost
+	}
+
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	return errgo.Mask(ioutil.WriteFile(b.path(lock.Swarm), data, 0600))
+}
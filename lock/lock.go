@@ -0,0 +1,221 @@
+// Package lock provides locks of swarms, so only one command changes a swarm at a time.
+package lock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	// DefaultLease is how long a lock is held, unless released before.
+	DefaultLease = time.Hour
+
+	// holderTimeLayout formats the times of a lock in error messages.
+	holderTimeLayout = "2006-01-02 15:04:05 MST"
+)
+
+// Info describes a lock of a swarm and its holder.
+type Info struct {
+	Swarm    string    `json:"swarm"`    // Swarm is the name of the locked swarm.
+	Token    string    `json:"token"`    // Token identifies the acquisition of the lock.
+	User     string    `json:"user"`     // User is the user holding the lock.
+	Host     string    `json:"host"`     // Host is the host the lock was acquired on.
+	Command  string    `json:"command"`  // Command is the kocho command holding the lock, e.g. kill-instance.
+	Acquired time.Time `json:"acquired"` // Acquired is when the lock was acquired.
+	Expires  time.Time `json:"expires"`  // Expires is when the lease of the lock ends.
+}
+
+// Expired returns whether the lease of the lock ended at the given time.
+func (i Info) Expired(now time.Time) bool {
+	return !now.Before(i.Expires)
+}
+
+// Holder describes who holds the lock, and since when.
+func (i Info) Holder() string {
+	return fmt.Sprintf("%s@%s running '%s' since %s, lease expires %s",
+		i.User, i.Host, i.Command, i.Acquired.Local().Format(holderTimeLayout), i.Expires.Local().Format(holderTimeLayout))
+}
+
+// ErrLockLost is returned when renewing a lock someone else took over after
+// its lease expired, or removed.
+var ErrLockLost = errgo.New("lock was lost")
+
+// LockedError is returned when a swarm is locked by someone else.
+type LockedError struct {
+	Lock Info
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("swarm %s is locked by %s", e.Lock.Swarm, e.Lock.Holder())
+}
+
+// IsLocked returns whether the error is a LockedError.
+func IsLocked(err error) bool {
+	_, ok := errgo.Cause(err).(*LockedError)
+	return ok
+}
+
+// Backend keeps the locks of swarms.
+type Backend interface {
+	// Create stores the lock, unless the swarm has a lock not expired at
+	// the given time. A LockedError holding that lock is returned then.
+	Create(lock Info, now time.Time) error
+
+	// Get returns the lock of the swarm, or nil if it isn't locked.
+	Get(swarm string) (*Info, error)
+
+	// Delete removes the lock of the swarm if it has the given token. An
+	// empty token removes any lock of the swarm.
+	Delete(swarm, token string) error
+
+	// Renew stores the lock with its new expiry time, if the swarm still has
+	// the lock with its token. ErrLockLost is returned otherwise.
+	Renew(lock Info) error
+}
+
+// NewLocker returns a Locker keeping the locks in the backend, acquired by the
+// given user on the given host for the duration of the lease.
+func NewLocker(backend Backend, lease time.Duration, user, host string) *Locker {
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+	return &Locker{
+		backend: backend,
+		lease:   lease,
+		user:    user,
+		host:    host,
+		now:     time.Now,
+	}
+}
+
+// Locker acquires and releases locks of swarms.
+type Locker struct {
+	backend Backend
+	lease   time.Duration
+	user    string
+	host    string
+	now     func() time.Time
+}
+
+// Acquire locks the swarm for the given command. It returns a LockedError if
+// the swarm is locked by someone else.
+func (l *Locker) Acquire(swarm, command string) (*Lock, error) {
+	now := l.now().UTC().Truncate(time.Second)
+	info := Info{
+		Swarm:    swarm,
+		Token:    newToken(),
+		User:     l.user,
+		Host:     l.host,
+		Command:  command,
+		Acquired: now,
+		Expires:  now.Add(l.lease),
+	}
+	if err := l.backend.Create(info, now); err != nil {
+		return nil, errgo.Mask(err, IsLocked)
+	}
+	return &Lock{Info: info, backend: l.backend, lease: l.lease, now: l.now}, nil
+}
+
+// Get returns the lock of the swarm, or nil if it isn't locked.
+func (l *Locker) Get(swarm string) (*Info, error) {
+	info, err := l.backend.Get(swarm)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return info, nil
+}
+
+// Unlock removes the lock of the swarm and returns it, or nil if the swarm
+// wasn't locked. Unless forced, only expired locks and locks acquired by the
+// same user on the same host are removed, and a LockedError is returned for others.
+func (l *Locker) Unlock(swarm string, force bool) (*Info, error) {
+	info, err := l.backend.Get(swarm)
+	if err != nil {
+		if !force {
+			return nil, errgo.Mask(err)
+		}
+		// An unreadable lock can still be removed
+		return nil, errgo.Mask(l.backend.Delete(swarm, ""))
+	}
+	if info == nil {
+		return nil, nil
+	}
+
+	ours := info.User == l.user && info.Host == l.host
+	if !force && !ours && !info.Expired(l.now()) {
+		return nil, &LockedError{Lock: *info}
+	}
+	if err := l.backend.Delete(swarm, info.Token); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return info, nil
+}
+
+// Lock is a lock of a swarm acquired by a Locker.
+type Lock struct {
+	Info
+	backend Backend
+	lease   time.Duration
+	now     func() time.Time
+}
+
+// Release removes the lock, unless someone else took it over after its lease expired.
+func (l *Lock) Release() error {
+	return errgo.Mask(l.backend.Delete(l.Swarm, l.Token))
+}
+
+// Renew extends the lease of the lock from now on. It returns ErrLockLost if
+// someone else took over the lock meanwhile.
+func (l *Lock) Renew() error {
+	info := l.Info
+	info.Expires = l.now().UTC().Truncate(time.Second).Add(l.lease)
+	if err := l.backend.Renew(info); err != nil {
+		return errgo.Mask(err, errgo.Is(ErrLockLost))
+	}
+	l.Info = info
+	return nil
+}
+
+// KeepRenewed renews the lock every half of its lease until the returned
+// function is called, so commands taking longer than the lease keep the lock.
+// Errors renewing the lock are passed to failed. The lock must not be used
+// until the returned function was called.
+func (l *Lock) KeepRenewed(failed func(error)) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(l.lease / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := l.Renew(); err != nil {
+					failed(err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// newToken returns a random token identifying the acquisition of a lock.
+func newToken() string {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(token)
+}
@@ -0,0 +1,143 @@
+package lock
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+func newTestLockers(backend Backend) (*Locker, *Locker, *time.Time) {
+	now := time.Date(2016, 4, 5, 13, 14, 15, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	alice := NewLocker(backend, time.Hour, "alice", "alice-laptop")
+	alice.now = clock
+	bob := NewLocker(backend, time.Hour, "bob", "bob-laptop")
+	bob.now = clock
+	return alice, bob, &now
+}
+
+func testLocker(t *testing.T, backend Backend) {
+	alice, bob, now := newTestLockers(backend)
+
+	lock, err := alice.Acquire("my-swarm", "kill-instance")
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+
+	_, err = bob.Acquire("my-swarm", "dns")
+	if !IsLocked(err) {
+		t.Fatalf("expected locked swarm, got %v", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "alice@alice-laptop") || !strings.Contains(msg, "kill-instance") {
+		t.Fatalf("expected holder in error message, got %q", msg)
+	}
+
+	if _, err := bob.Acquire("other-swarm", "dns"); err != nil {
+		t.Fatalf("expected other swarms not to be locked, got %v", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Failed to release lock: %v", err)
+	}
+	lock, err = bob.Acquire("my-swarm", "dns")
+	if err != nil {
+		t.Fatalf("Failed to acquire released lock: %v", err)
+	}
+
+	// The expired lock is taken over, and releasing it doesn't remove the new one
+	*now = now.Add(2 * time.Hour)
+	if _, err := alice.Acquire("my-swarm", "update"); err != nil {
+		t.Fatalf("Failed to take over expired lock: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Failed to release expired lock: %v", err)
+	}
+	info, err := alice.Get("my-swarm")
+	if err != nil || info == nil || info.User != "alice" {
+		t.Fatalf("expected lock of alice, got %#v %v", info, err)
+	}
+}
+
+func testUnlock(t *testing.T, backend Backend) {
+	alice, bob, _ := newTestLockers(backend)
+
+	if _, err := alice.Acquire("my-swarm", "kill-instance"); err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+
+	if _, err := bob.Unlock("my-swarm", false); !IsLocked(err) {
+		t.Fatalf("expected unlocking the lock of someone else to fail, got %v", err)
+	}
+	info, err := bob.Unlock("my-swarm", true)
+	if err != nil || info == nil || info.User != "alice" {
+		t.Fatalf("expected forced unlock to remove lock of alice, got %#v %v", info, err)
+	}
+
+	if _, err := alice.Acquire("my-swarm", "kill-instance"); err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+	if info, err := alice.Unlock("my-swarm", false); err != nil || info == nil {
+		t.Fatalf("expected unlocking own lock to succeed, got %#v %v", info, err)
+	}
+	if info, err := alice.Unlock("my-swarm", false); err != nil || info != nil {
+		t.Fatalf("expected nothing to unlock, got %#v %v", info, err)
+	}
+}
+
+func testRenew(t *testing.T, backend Backend) {
+	alice, bob, now := newTestLockers(backend)
+
+	lock, err := alice.Acquire("my-swarm", "update")
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+
+	// A renewed lock isn't taken over once its original lease ended
+	*now = now.Add(50 * time.Minute)
+	if err := lock.Renew(); err != nil {
+		t.Fatalf("Failed to renew lock: %v", err)
+	}
+	*now = now.Add(50 * time.Minute)
+	if _, err := bob.Acquire("my-swarm", "dns"); !IsLocked(err) {
+		t.Fatalf("expected renewed lock to be held, got %v", err)
+	}
+
+	*now = now.Add(time.Hour)
+	if _, err := bob.Acquire("my-swarm", "dns"); err != nil {
+		t.Fatalf("Failed to take over expired lock: %v", err)
+	}
+	if err := lock.Renew(); errgo.Cause(err) != ErrLockLost {
+		t.Fatalf("expected renewing a lock taken over to fail, got %v", err)
+	}
+}
+
+func newTestFileBackend(t *testing.T) (*FileBackend, func()) {
+	dir, err := ioutil.TempDir("", "kocho-lock")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	return NewFileBackend(dir), func() { os.RemoveAll(dir) }
+}
+
+func TestFileBackendLocker(t *testing.T) {
+	backend, cleanup := newTestFileBackend(t)
+	defer cleanup()
+	testLocker(t, backend)
+}
+
+func TestFileBackendUnlock(t *testing.T) {
+	backend, cleanup := newTestFileBackend(t)
+	defer cleanup()
+	testUnlock(t, backend)
+}
+
+func TestFileBackendRenew(t *testing.T) {
+	backend, cleanup := newTestFileBackend(t)
+	defer cleanup()
+	testRenew(t, backend)
+}
@@ -0,0 +1,42 @@
+package notification
+
+// Configuration describes the notification receivers configured in the kocho
+// configuration file.
+type Configuration struct {
+	Slack     *SlackConfiguration    `mapstructure:"slack"`     // Slack receives all events, routed to channels.
+	Email     *EmailConfiguration    `mapstructure:"email"`     // Email receives all events, routed to recipients.
+	Webhooks  []WebhookConfiguration `mapstructure:"webhooks"`  // Webhooks receive all events as JSON.
+	Templates map[string]string      `mapstructure:"templates"` // Templates render the text of events, by command.
+}
+
+// NewNotifiers returns the notifiers for all receivers of the configuration.
+func NewNotifiers(config Configuration) (Notifiers, error) {
+	templates, err := NewTemplates(config.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifiers Notifiers
+	if config.Slack != nil {
+		notifier, err := NewSlackNotifier(*config.Slack, templates)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	if config.Email != nil {
+		notifier, err := NewEmailNotifier(*config.Email, templates)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	for _, webhook := range config.Webhooks {
+		notifier, err := NewWebhookNotifier(webhook, templates)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, nil
+}
@@ -0,0 +1,208 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	defaultSMTPPort = 587
+	smtpTimeout     = 10 * time.Second
+)
+
+// EmailConfiguration describes a configuration for sending events by email via SMTP.
+type EmailConfiguration struct {
+	Host     string `mapstructure:"host"`     // Host is the SMTP server to send emails with.
+	Port     int    `mapstructure:"port"`     // Port of the SMTP server, 587 by default.
+	Username string `mapstructure:"username"` // Username to authenticate with, if not empty.
+	Password string `mapstructure:"password"` // Password to authenticate with.
+	StartTLS bool   `mapstructure:"starttls"` // StartTLS fails sending if the connection can't be upgraded to TLS.
+
+	From       string       `mapstructure:"from"`       // From is the sender of the emails.
+	Recipients []string     `mapstructure:"recipients"` // Recipients receive events no route matches.
+	Routes     []EmailRoute `mapstructure:"routes"`     // Routes send events to other recipients.
+}
+
+// EmailRoute sends matching events to a list of recipients.
+type EmailRoute struct {
+	Match      `mapstructure:",squash"`
+	Recipients []string `mapstructure:"recipients"` // Recipients receive matching events.
+}
+
+// NewEmailNotifier returns an EmailNotifier for the given configuration,
+// rendering emails with the given templates.
+func NewEmailNotifier(config EmailConfiguration, templates *Templates) (*EmailNotifier, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, errgo.WithCausef(nil, ErrInvalidConfiguration, "SMTP host and sender of emails must be set")
+	}
+	if config.Port == 0 {
+		config.Port = defaultSMTPPort
+	}
+	return &EmailNotifier{
+		config:    config,
+		templates: templates,
+	}, nil
+}
+
+// EmailNotifier sends events by email.
+type EmailNotifier struct {
+	config    EmailConfiguration
+	templates *Templates
+}
+
+// Notify sends an email about the event to the recipients of the first route
+// matching the event, or the default recipients. Nothing is sent if there are
+// no recipients.
+func (n *EmailNotifier) Notify(event Event) error {
+	recipients := n.recipientsOf(event)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	text, err := n.templates.Render(event)
+	if err != nil {
+		return err
+	}
+	message, err := newEmailMessage(n.config.From, recipients, event, text)
+	if err != nil {
+		return err
+	}
+
+	if err := n.send(recipients, message); err != nil {
+		return errgo.Notef(err, "failed to send email via %s", n.config.Host)
+	}
+	return nil
+}
+
+// recipientsOf returns the recipients of the first route matching the event,
+// or the default recipients if no route matches.
+func (n *EmailNotifier) recipientsOf(event Event) []string {
+	for _, route := range n.config.Routes {
+		if route.Matches(event) {
+			return route.Recipients
+		}
+	}
+	return n.config.Recipients
+}
+
+// send sends the message to the recipients via the SMTP server. The connection
+// is upgraded to TLS whenever the server supports STARTTLS.
+func (n *EmailNotifier) send(recipients []string, message []byte) error {
+	address := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	conn, err := net.DialTimeout("tcp", address, smtpTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return err
+		}
+	} else if n.config.StartTLS {
+		return errgo.Newf("server does not support STARTTLS")
+	}
+	if n.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailHTML renders the HTML body of emails.
+var emailHTML = template.Must(template.New("email").Parse(`<html>
+<body>
+<p>{{.Text}}</p>
+<table>
+{{range .Fields}}<tr><th align="left">{{.Title}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// newEmailMessage returns the email about the event, with plain text and HTML
+// bodies showing the text and the fields of the event. Lines end with \n, which
+// the SMTP client converts to \r\n.
+func newEmailMessage(from string, recipients []string, event Event, text string) ([]byte, error) {
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	subject := fmt.Sprintf("[kocho] %s %s %s", event.Command, event.Swarm, event.Result())
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\n", from)
+	fmt.Fprintf(&message, "To: %s\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&message, "Subject: %s\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\n\n", boundary)
+
+	fmt.Fprintf(&message, "--%s\nContent-Type: text/plain; charset=utf-8\n\n", boundary)
+	fmt.Fprintf(&message, "%s\n\n", text)
+	for _, field := range event.Fields() {
+		fmt.Fprintf(&message, "%s: %s\n", field.Title, field.Value)
+	}
+
+	fmt.Fprintf(&message, "\n--%s\nContent-Type: text/html; charset=utf-8\n\n", boundary)
+	if err := emailHTML.Execute(&message, map[string]interface{}{
+		"Text":   text,
+		"Fields": event.Fields(),
+	}); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	fmt.Fprintf(&message, "\n--%s--\n", boundary)
+
+	return message.Bytes(), nil
+}
+
+// newBoundary returns a random boundary of MIME multipart messages.
+func newBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,163 @@
+package notification
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// smtpStandIn is a minimal SMTP server accepting a single email.
+type smtpStandIn struct {
+	listener   net.Listener
+	from       string
+	recipients []string
+	data       string
+	done       chan struct{}
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &smtpStandIn{listener: listener, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.recipients = append(s.recipients, line)
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			var data []string
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data = append(data, line)
+			}
+			s.data = strings.Join(data, "")
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	server := newSMTPStandIn(t)
+	defer server.listener.Close()
+
+	notifier, err := NewEmailNotifier(EmailConfiguration{
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		From:       "kocho@example.com",
+		Recipients: []string{"dev@example.com"},
+		Routes: []EmailRoute{
+			{Match: Match{Commands: []string{"destroy"}, SwarmTypes: []string{"primary"}}, Recipients: []string{"ops@example.com", "lead@example.com"}},
+		},
+	}, mustTemplates(t, map[string]string{"destroy": "{{.User}} destroyed <{{.Swarm}}>"}))
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	event := NewEvent("destroy", []string{"kocho", "destroy", "my-swarm"}, "alice", "1.0.0", "abc")
+	event.Swarm = "my-swarm"
+	event.SwarmType = "primary"
+	event.Success = true
+	if err := notifier.Notify(event); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+	<-server.done
+
+	if server.from != "MAIL FROM:<kocho@example.com>" {
+		t.Errorf("unexpected sender: %s", server.from)
+	}
+	if strings.Join(server.recipients, ",") != "RCPT TO:<ops@example.com>,RCPT TO:<lead@example.com>" {
+		t.Errorf("expected recipients of the matching route, got %v", server.recipients)
+	}
+
+	for _, expected := range []string{
+		"Subject: [kocho] destroy my-swarm succeeded\r\n",
+		"To: ops@example.com, lead@example.com\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n\r\nalice destroyed <my-swarm>\r\n",
+		"Swarm: my-swarm\r\n",
+		"Content-Type: text/html; charset=utf-8\r\n",
+		"<p>alice destroyed &lt;my-swarm&gt;</p>",
+		"<tr><th align=\"left\">Result</th><td>succeeded</td></tr>",
+	} {
+		if !strings.Contains(server.data, expected) {
+			t.Errorf("expected email to contain %q, got:\n%s", expected, server.data)
+		}
+	}
+}
+
+func TestEmailNotifierWithoutRecipients(t *testing.T) {
+	notifier, err := NewEmailNotifier(EmailConfiguration{
+		Host: "127.0.0.1",
+		Port: 1,
+		From: "kocho@example.com",
+	}, mustTemplates(t, nil))
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	// Without recipients, no connection to the SMTP server is made
+	if err := notifier.Notify(Event{Command: "create"}); err != nil {
+		t.Fatalf("expected no email to be sent, got %v", err)
+	}
+}
+
+func TestEmailConfiguration(t *testing.T) {
+	if _, err := NewEmailNotifier(EmailConfiguration{Host: "smtp.example.com"}, mustTemplates(t, nil)); !IsInvalidConfiguration(err) {
+		t.Fatalf("expected ErrInvalidConfiguration, got %v", err)
+	}
+
+	notifier, err := NewEmailNotifier(EmailConfiguration{Host: "smtp.example.com", From: "kocho@example.com"}, mustTemplates(t, nil))
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+	if notifier.config.Port != defaultSMTPPort {
+		t.Fatalf("expected default port %d, got %d", defaultSMTPPort, notifier.config.Port)
+	}
+}
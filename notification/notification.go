@@ -1,6 +1,12 @@
 package notification
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/juju/errgo"
 )
 
@@ -16,3 +22,155 @@ func IsNotConfigured(err error) bool {
 func IsInvalidConfiguration(err error) bool {
 	return errgo.Cause(err) == ErrInvalidConfiguration
 }
+
+// Event describes a command of kocho to notify about, and its outcome.
+type Event struct {
+	Id          string `json:"id"`           // Id identifies the event, e.g. to relate progress to it.
+	Command     string `json:"command"`      // Command is the kocho command, e.g. create.
+	CommandLine string `json:"command_line"` // CommandLine is the full command line kocho was invoked with.
+
+	Swarm     string `json:"swarm,omitempty"`      // Swarm is the name of the swarm the command changed.
+	SwarmType string `json:"swarm_type,omitempty"` // SwarmType is the type of the swarm, e.g. primary.
+	Provider  string `json:"provider,omitempty"`   // Provider is the provider the swarm runs on.
+	Region    string `json:"region,omitempty"`     // Region is the region the swarm runs in.
+
+	Success  bool          `json:"success"`         // Success is whether the command succeeded.
+	Error    string        `json:"error,omitempty"` // Error is the error message of a failed command.
+	Duration time.Duration `json:"duration"`        // Duration is how long the command ran, in nanoseconds.
+
+	Instances  []string `json:"instances,omitempty"`   // Instances are the IDs of the instances the command killed.
+	DNSEntries []string `json:"dns_entries,omitempty"` // DNSEntries are the DNS entries the command changed.
+
+	User    string    `json:"user"`    // User is the user running kocho.
+	Version string    `json:"version"` // Version is the version of kocho.
+	Build   string    `json:"build"`   // Build is the build of kocho.
+	Time    time.Time `json:"time"`    // Time is when the command started.
+}
+
+// NewEvent returns an Event for the given command and command line, starting now.
+func NewEvent(command string, args []string, user, version, build string) Event {
+	return Event{
+		Id:          newEventId(),
+		Command:     command,
+		CommandLine: strings.Join(args, " "),
+		User:        user,
+		Version:     version,
+		Build:       build,
+		Time:        time.Now().UTC(),
+	}
+}
+
+// newEventId returns a random ID for an event.
+func newEventId() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id)
+}
+
+// Result returns a short description of the outcome of the event.
+func (e Event) Result() string {
+	if e.Success {
+		return "succeeded"
+	}
+	return "failed"
+}
+
+// Field is a titled value describing an event, e.g. to render it as table.
+type Field struct {
+	Title string
+	Value string
+	Short bool // Short values can be shown next to each other.
+}
+
+// Fields returns the fields describing the event, leaving out empty ones.
+func (e Event) Fields() []Field {
+	var fields []Field
+	add := func(title, value string, short bool) {
+		if value != "" {
+			fields = append(fields, Field{Title: title, Value: value, Short: short})
+		}
+	}
+
+	add("Swarm", e.Swarm, true)
+	add("Type", e.SwarmType, true)
+	add("Provider", e.Provider, true)
+	add("Region", e.Region, true)
+	add("Result", e.Result(), true)
+	if e.Duration > 0 {
+		add("Duration", (e.Duration / time.Second * time.Second).String(), true)
+	}
+	add("Error", e.Error, false)
+	add("Instances", strings.Join(e.Instances, ", "), false)
+	add("DNS Entries", strings.Join(e.DNSEntries, ", "), false)
+	add("Kocho Version", e.Version, true)
+	add("Kocho Build", e.Build, true)
+	return fields
+}
+
+// Notifier sends notifications about events to a receiver.
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// ProgressNotifier is a Notifier that also notifies about the start and the
+// progress of long running commands, before Notify notifies about their outcome.
+type ProgressNotifier interface {
+	Notifier
+	NotifyStart(event Event) error
+	NotifyProgress(event Event, message string) error
+}
+
+// Notifiers sends notifications to multiple receivers.
+type Notifiers []Notifier
+
+// NotifyStart notifies all progress notifiers about the start of the command
+// of the event. Other notifiers are skipped.
+func (notifiers Notifiers) NotifyStart(event Event) error {
+	return notifiers.notifyProgress(func(notifier ProgressNotifier) error {
+		return notifier.NotifyStart(event)
+	})
+}
+
+// NotifyProgress notifies all progress notifiers about the progress of the
+// command of the event. Other notifiers are skipped.
+func (notifiers Notifiers) NotifyProgress(event Event, message string) error {
+	return notifiers.notifyProgress(func(notifier ProgressNotifier) error {
+		return notifier.NotifyProgress(event, message)
+	})
+}
+
+func (notifiers Notifiers) notifyProgress(notify func(ProgressNotifier) error) error {
+	var messages []string
+	for _, notifier := range notifiers {
+		if progressNotifier, ok := notifier.(ProgressNotifier); ok {
+			if err := notify(progressNotifier); err != nil {
+				messages = append(messages, err.Error())
+			}
+		}
+	}
+	if len(messages) > 0 {
+		return errgo.Newf("%d progress notifications failed: %s", len(messages), strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// Notify sends the event to all notifiers, also if some of them fail. It
+// returns ErrNotConfigured if there are no notifiers.
+func (notifiers Notifiers) Notify(event Event) error {
+	if len(notifiers) == 0 {
+		return errgo.Mask(ErrNotConfigured, errgo.Any)
+	}
+
+	var messages []string
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(event); err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	if len(messages) > 0 {
+		return errgo.Newf("%d of %d notifications failed: %s", len(messages), len(notifiers), strings.Join(messages, "; "))
+	}
+	return nil
+}
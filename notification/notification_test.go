@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEventFields(t *testing.T) {
+	event := Event{
+		Command:   "kill-instance",
+		Swarm:     "my-swarm",
+		SwarmType: "primary",
+		Provider:  "aws",
+		Region:    "eu-west-1",
+		Error:     "failed to update dns records",
+		Duration:  83*time.Second + 400*time.Millisecond,
+		Instances: []string{"i-1", "i-2"},
+		Version:   "1.0.0",
+	}
+
+	expected := []Field{
+		{"Swarm", "my-swarm", true},
+		{"Type", "primary", true},
+		{"Provider", "aws", true},
+		{"Region", "eu-west-1", true},
+		{"Result", "failed", true},
+		{"Duration", "1m23s", true},
+		{"Error", "failed to update dns records", false},
+		{"Instances", "i-1, i-2", false},
+		{"Kocho Version", "1.0.0", true},
+	}
+	if fields := event.Fields(); !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected fields %#v, got %#v", expected, fields)
+	}
+}
@@ -0,0 +1,49 @@
+package notification
+
+// Match matches events meeting all of its conditions. Empty conditions match
+// all events.
+type Match struct {
+	Commands   []string `mapstructure:"commands"`    // Commands to match, e.g. destroy.
+	SwarmTypes []string `mapstructure:"swarm_types"` // SwarmTypes to match, e.g. primary.
+	Result     string   `mapstructure:"result"`      // Result to match, succeeded or failed.
+}
+
+// Matches returns whether the event meets all conditions.
+func (m Match) Matches(event Event) bool {
+	if len(m.Commands) > 0 && !contains(m.Commands, event.Command) {
+		return false
+	}
+	if len(m.SwarmTypes) > 0 && !contains(m.SwarmTypes, event.SwarmType) {
+		return false
+	}
+	if m.Result != "" && m.Result != event.Result() {
+		return false
+	}
+	return true
+}
+
+// Route sends matching events to a Slack channel.
+type Route struct {
+	Match   `mapstructure:",squash"`
+	Channel string `mapstructure:"channel"` // Channel is where matching events are sent to.
+}
+
+// channelOf returns the channel of the first route matching the event, or the
+// default channel if no route matches.
+func channelOf(routes []Route, event Event, defaultChannel string) string {
+	for _, route := range routes {
+		if route.Matches(event) {
+			return route.Channel
+		}
+	}
+	return defaultChannel
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package notification
+
+import (
+	"testing"
+)
+
+func TestChannelOf(t *testing.T) {
+	routes := []Route{
+		{Match: Match{Commands: []string{"destroy"}, SwarmTypes: []string{"primary"}}, Channel: "#ops-alerts"},
+		{Match: Match{Result: "failed"}, Channel: "#ops"},
+		{Match: Match{Commands: []string{"create", "clone"}, SwarmTypes: []string{"standalone"}}, Channel: "#dev"},
+	}
+
+	tests := []struct {
+		event    Event
+		expected string
+	}{
+		{Event{Command: "destroy", SwarmType: "primary", Success: true}, "#ops-alerts"},
+		{Event{Command: "destroy", SwarmType: "primary"}, "#ops-alerts"},
+		{Event{Command: "destroy", SwarmType: "standalone", Success: true}, "#kocho"},
+		{Event{Command: "create", SwarmType: "standalone"}, "#ops"},
+		{Event{Command: "clone", SwarmType: "standalone", Success: true}, "#dev"},
+		{Event{Command: "create", SwarmType: "secondary", Success: true}, "#kocho"},
+	}
+
+	for _, test := range tests {
+		if channel := channelOf(routes, test.event, "#kocho"); channel != test.expected {
+			t.Errorf("expected %s for %#v, got %s", test.expected, test.event, channel)
+		}
+	}
+}
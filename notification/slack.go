@@ -1,11 +1,14 @@
-// Package notification can be used to send notifications to your Slack team.
+// Package notification can be used to send notifications about kocho's
+// invocations to Slack, by email and to webhooks.
 package notification
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/juju/errgo"
@@ -28,92 +31,257 @@ func init() {
 	}
 }
 
-// SlackConfiguration describes a configuration for posting to a Slack channel.
+// SlackConfiguration describes a configuration for posting to Slack channels.
 type SlackConfiguration struct {
-	Token                string `json:"token"`                           // Token is the API token from Slack.
-	Username             string `json:"username"`                        // Username is your username.
-	NotificationUsername string `json:"notification_username,omitempty"` // NotificationUsername is the username the notification should be posted under.
-	EmojiIcon            string `json:"emoji_icon,omitempty"`            // EmojiIcon is an emoji (e.g: :smile:) to use as an avatar for the notification.
-	NotificationChannel  string `json:"notification_channel"`            // NotificationChannel is the channel to post to.
+	Token                string  `json:"token" mapstructure:"token"`                                           // Token is the API token from Slack.
+	WebhookURL           string  `json:"webhook_url,omitempty" mapstructure:"webhook_url"`                     // WebhookURL is the URL of an incoming webhook, used if there is no token.
+	Username             string  `json:"username" mapstructure:"username"`                                     // Username is your username, shown instead of the local user.
+	NotificationUsername string  `json:"notification_username,omitempty" mapstructure:"notification_username"` // NotificationUsername is the username the notification should be posted under.
+	EmojiIcon            string  `json:"emoji_icon,omitempty" mapstructure:"emoji_icon"`                       // EmojiIcon is an emoji (e.g: :smile:) to use as an avatar for the notification.
+	NotificationChannel  string  `json:"notification_channel" mapstructure:"notification_channel"`             // NotificationChannel is the channel to post to if no route matches.
+	Routes               []Route `json:"-" mapstructure:"routes"`                                              // Routes send events to other channels.
 }
 
-// WriteConfig writes the given SlackConfiguration to the configuration file.
-func WriteConfig(config SlackConfiguration) error {
+// setDefaults sets the bot username and emoji if they are not configured.
+func (c *SlackConfiguration) setDefaults() {
+	if c.NotificationUsername == "" {
+		c.NotificationUsername = "KochoBot"
+	}
+	if c.EmojiIcon == "" {
+		c.EmojiIcon = ":robot_face:"
+	}
+}
+
+// LoadLegacySlackConfig reads the Slack configuration from the slack.conf file
+// used before Slack was configured in the kocho configuration file. It returns
+// ErrNotConfigured if there is no such file.
+func LoadLegacySlackConfig() (SlackConfiguration, error) {
+	slackConfiguration := SlackConfiguration{}
+
 	expanded, err := homedir.Expand(configPath)
 	if err != nil {
-		return err
+		return slackConfiguration, err
 	}
-
-	configFolder := filepath.Dir(expanded)
-	if err := os.MkdirAll(configFolder, 0777); err != nil {
-		return err
+	if _, err := os.Stat(expanded); os.IsNotExist(err) {
+		return slackConfiguration, errgo.Mask(ErrNotConfigured, errgo.Any)
 	}
-	configFile, err := os.Create(expanded)
+
+	configFile, err := os.Open(expanded)
 	if err != nil {
-		return err
+		return slackConfiguration, errgo.WithCausef(err, ErrInvalidConfiguration, "couldn't open Slack configuration file")
 	}
 	defer configFile.Close()
 
-	if err := json.NewEncoder(configFile).Encode(config); err != nil {
-		return err
+	if err := json.NewDecoder(configFile).Decode(&slackConfiguration); err != nil {
+		return slackConfiguration, errgo.WithCausef(err, ErrInvalidConfiguration, "couldn't decode Slack configuration")
 	}
 
-	return nil
+	return slackConfiguration, nil
 }
 
-// SendMessage reads the configuration file, and posts a message about Kocho's invocation to Slack.
-func SendMessage(version, build string) error {
-	expanded, err := homedir.Expand(configPath)
+// NewSlackNotifier returns a SlackNotifier for the given configuration, rendering
+// messages with the given templates.
+func NewSlackNotifier(config SlackConfiguration, templates *Templates) (*SlackNotifier, error) {
+	if config.Token == "" && config.WebhookURL == "" {
+		return nil, errgo.WithCausef(nil, ErrInvalidConfiguration, "Slack token or webhook URL must be set")
+	}
+	for _, route := range config.Routes {
+		if route.Channel == "" {
+			return nil, errgo.WithCausef(nil, ErrInvalidConfiguration, "channel of Slack route must be set")
+		}
+	}
+	config.setDefaults()
+
+	notifier := &SlackNotifier{
+		config:    config,
+		templates: templates,
+		threads:   map[string]slackThread{},
+	}
+	if config.Token != "" {
+		notifier.client = slack.New(config.Token)
+	} else {
+		notifier.httpClient = &http.Client{Timeout: webhookTimeout}
+	}
+	return notifier, nil
+}
+
+// SlackNotifier posts events to Slack channels, either with the API token or
+// to an incoming webhook. With the API token, the progress and outcome of long
+// running commands are posted as replies to the message about their start.
+type SlackNotifier struct {
+	config    SlackConfiguration
+	templates *Templates
+
+	client     *slack.Client
+	httpClient *http.Client
+
+	// threads holds the messages about the start of commands, by event ID
+	threads map[string]slackThread
+}
+
+// slackThread identifies the message starting a thread.
+type slackThread struct {
+	channel   string
+	timestamp string
+}
+
+// slackMessage describes a message to post.
+type slackMessage struct {
+	channel     string
+	text        string
+	attachments []slack.Attachment
+
+	// thread to reply to, and whether to also show the reply in the channel
+	thread         slackThread
+	replyBroadcast bool
+}
+
+// NotifyStart posts a message about the start of the command of the event.
+func (n *SlackNotifier) NotifyStart(event Event) error {
+	// The result is not known yet, so only routes independent of it apply
+	var routes []Route
+	for _, route := range n.config.Routes {
+		if route.Result == "" {
+			routes = append(routes, route)
+		}
+	}
+	channel := channelOf(routes, event, n.config.NotificationChannel)
+
+	timestamp, err := n.post(slackMessage{
+		channel: channel,
+		text:    fmt.Sprintf("*Kocho*: %s started `%s`", n.username(event), event.CommandLine),
+	})
 	if err != nil {
 		return err
 	}
-	if _, err := os.Stat(expanded); os.IsNotExist(err) {
-		return errgo.Mask(ErrNotConfigured, errgo.Any)
+
+	if timestamp != "" {
+		n.threads[event.Id] = slackThread{channel: channel, timestamp: timestamp}
 	}
+	return nil
+}
 
-	slackConfiguration := SlackConfiguration{
-		NotificationUsername: "KochoBot",
-		EmojiIcon:            ":robot_face:",
+// NotifyProgress replies to the message about the start of the command of the
+// event. Incoming webhooks cannot reply to messages, so nothing is posted then.
+func (n *SlackNotifier) NotifyProgress(event Event, message string) error {
+	thread, ok := n.threads[event.Id]
+	if !ok {
+		return nil
 	}
 
-	configFile, err := os.Open(expanded)
+	_, err := n.post(slackMessage{
+		channel: thread.channel,
+		text:    message,
+		thread:  thread,
+	})
+	return err
+}
+
+// Notify posts a message about the event to the channel of the first route
+// matching the event, or the notification channel. If the start of the command
+// was posted to the same channel, the message is posted as reply to it, and
+// also shown in the channel.
+func (n *SlackNotifier) Notify(event Event) error {
+	event.User = n.username(event)
+	text, err := n.templates.Render(event)
 	if err != nil {
-		return errgo.WithCausef(err, ErrInvalidConfiguration, "couldn't open Slack configuration file")
+		return err
 	}
-	defer configFile.Close()
 
-	if err := json.NewDecoder(configFile).Decode(&slackConfiguration); err != nil {
-		return errgo.WithCausef(err, ErrInvalidConfiguration, "couldn't decode Slack configuration")
+	color := "#2484BE"
+	if !event.Success {
+		color = "danger"
 	}
 
-	client := slack.New(slackConfiguration.Token)
+	var fields []slack.AttachmentField
+	for _, field := range event.Fields() {
+		fields = append(fields, slack.AttachmentField{
+			Title: field.Title,
+			Value: field.Value,
+			Short: field.Short,
+		})
+	}
 
-	params := slack.PostMessageParameters{}
-	params.Attachments = []slack.Attachment{
-		slack.Attachment{
-			Color: "#2484BE",
-			Text:  fmt.Sprintf("*Kocho*: %s ran `%s`", slackConfiguration.Username, strings.Join(os.Args, " ")),
-			Fields: []slack.AttachmentField{
-				slack.AttachmentField{
-					Title: "Kocho Version",
-					Value: version,
-					Short: true,
-				},
-				slack.AttachmentField{
-					Title: "Kocho Build",
-					Value: build,
-					Short: true,
-				},
+	message := slackMessage{
+		channel: channelOf(n.config.Routes, event, n.config.NotificationChannel),
+		attachments: []slack.Attachment{
+			slack.Attachment{
+				Color:      color,
+				Text:       text,
+				Fields:     fields,
+				MarkdownIn: []string{"text"},
 			},
-			MarkdownIn: []string{"text"},
 		},
 	}
-	params.Username = slackConfiguration.NotificationUsername
-	params.IconEmoji = slackConfiguration.EmojiIcon
+	if thread, ok := n.threads[event.Id]; ok && thread.channel == message.channel {
+		message.thread = thread
+		message.replyBroadcast = true
+	}
+	delete(n.threads, event.Id)
 
-	if _, _, err := client.PostMessage(slackConfiguration.NotificationChannel, "", params); err != nil {
-		return err
+	_, err = n.post(message)
+	return err
+}
+
+// username returns the name to show for the user of the event.
+func (n *SlackNotifier) username(event Event) string {
+	if n.config.Username != "" {
+		return n.config.Username
+	}
+	return event.User
+}
+
+// post posts the message, and returns its timestamp if it is known.
+func (n *SlackNotifier) post(message slackMessage) (string, error) {
+	if n.client == nil {
+		return "", n.postWebhook(message)
 	}
 
+	params := slack.PostMessageParameters{}
+	params.Attachments = message.attachments
+	params.Username = n.config.NotificationUsername
+	params.IconEmoji = n.config.EmojiIcon
+	params.ThreadTimestamp = message.thread.timestamp
+	params.ReplyBroadcast = message.replyBroadcast
+
+	_, timestamp, err := n.client.PostMessage(message.channel, message.text, params)
+	if err != nil {
+		return "", err
+	}
+	return timestamp, nil
+}
+
+// slackWebhookMessage is the payload of Slack incoming webhooks.
+type slackWebhookMessage struct {
+	Channel     string             `json:"channel,omitempty"`
+	Username    string             `json:"username,omitempty"`
+	IconEmoji   string             `json:"icon_emoji,omitempty"`
+	Text        string             `json:"text,omitempty"`
+	Attachments []slack.Attachment `json:"attachments,omitempty"`
+}
+
+// postWebhook posts the message to the incoming webhook.
+func (n *SlackNotifier) postWebhook(message slackMessage) error {
+	payload, err := json.Marshal(slackWebhookMessage{
+		Channel:     message.channel,
+		Username:    n.config.NotificationUsername,
+		IconEmoji:   n.config.EmojiIcon,
+		Text:        message.text,
+		Attachments: message.attachments,
+	})
+	if err != nil {
+		return errgo.Mask(err)
+	}
+
+	resp, err := n.httpClient.Post(n.config.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return errgo.Notef(err, "failed to post to Slack webhook")
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return errgo.Newf("Slack webhook responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
 	return nil
 }
@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlackNotifierWebhook(t *testing.T) {
+	var messages []slackWebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message slackWebhookMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Fatalf("Invalid payload: %v", err)
+		}
+		messages = append(messages, message)
+	}))
+	defer server.Close()
+
+	notifier, err := NewSlackNotifier(SlackConfiguration{
+		WebhookURL:          server.URL,
+		NotificationChannel: "#kocho",
+		Routes: []Route{
+			{Match: Match{Commands: []string{"destroy"}}, Channel: "#ops"},
+			{Match: Match{Result: "failed"}, Channel: "#alerts"},
+		},
+	}, mustTemplates(t, map[string]string{"destroy": "{{.User}} destroyed {{.Swarm}}"}))
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	event := NewEvent("destroy", []string{"kocho", "destroy", "my-swarm"}, "alice", "1.0.0", "abc")
+	event.Swarm = "my-swarm"
+	if err := notifier.NotifyStart(event); err != nil {
+		t.Fatalf("Failed to notify start: %v", err)
+	}
+	if err := notifier.NotifyProgress(event, "waiting for deletion"); err != nil {
+		t.Fatalf("Failed to notify progress: %v", err)
+	}
+	event.Success = true
+	if err := notifier.Notify(event); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+
+	// Incoming webhooks cannot reply to messages, so progress is not posted
+	if len(messages) != 2 {
+		t.Fatalf("expected start and outcome to be posted, got %#v", messages)
+	}
+	if messages[0].Channel != "#ops" || messages[0].Text != "*Kocho*: alice started `kocho destroy my-swarm`" {
+		t.Errorf("unexpected start message: %#v", messages[0])
+	}
+	if messages[1].Channel != "#ops" || len(messages[1].Attachments) != 1 || messages[1].Attachments[0].Text != "alice destroyed my-swarm" {
+		t.Errorf("unexpected outcome message: %#v", messages[1])
+	}
+	if messages[0].Username != "KochoBot" {
+		t.Errorf("expected default bot username, got %s", messages[0].Username)
+	}
+}
+
+func TestSlackNotifierConfiguration(t *testing.T) {
+	tests := []SlackConfiguration{
+		{NotificationChannel: "#kocho"},
+		{Token: "token", Routes: []Route{{Match: Match{Commands: []string{"create"}}}}},
+	}
+
+	for _, config := range tests {
+		if _, err := NewSlackNotifier(config, mustTemplates(t, nil)); !IsInvalidConfiguration(err) {
+			t.Errorf("expected ErrInvalidConfiguration for %#v, got %v", config, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	// DefaultTemplate renders events without a template configured for their command.
+	DefaultTemplate = "Kocho: {{.User}} ran `{{.CommandLine}}`, which {{.Result}}"
+
+	// defaultTemplateName is the name of the template configured for all commands
+	// without a template of their own.
+	defaultTemplateName = "default"
+)
+
+// NewTemplates parses the given Go templates, by command they render the text
+// of events of. The template named "default" is used for all other commands.
+func NewTemplates(texts map[string]string) (*Templates, error) {
+	templates := &Templates{
+		templates: map[string]*template.Template{},
+	}
+	if _, ok := texts[defaultTemplateName]; !ok {
+		templates.templates[defaultTemplateName] = template.Must(template.New(defaultTemplateName).Parse(DefaultTemplate))
+	}
+
+	for command, text := range texts {
+		t, err := template.New(command).Parse(text)
+		if err != nil {
+			return nil, errgo.WithCausef(err, ErrInvalidConfiguration, "invalid notification template for %s", command)
+		}
+		templates.templates[command] = t
+	}
+	return templates, nil
+}
+
+// Templates renders the text of events.
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// Render returns the text of the event, rendered with the template of its command.
+func (t *Templates) Render(event Event) (string, error) {
+	tmpl, ok := t.templates[event.Command]
+	if !ok {
+		tmpl = t.templates[defaultTemplateName]
+	}
+
+	var text bytes.Buffer
+	if err := tmpl.Execute(&text, event); err != nil {
+		return "", errgo.Notef(err, "failed to render notification for %s", event.Command)
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"testing"
+)
+
+func mustTemplates(t *testing.T, texts map[string]string) *Templates {
+	templates, err := NewTemplates(texts)
+	if err != nil {
+		t.Fatalf("Invalid templates: %v", err)
+	}
+	return templates
+}
+
+func TestTemplatesRender(t *testing.T) {
+	event := Event{
+		Command:     "destroy",
+		CommandLine: "kocho destroy my-swarm",
+		Swarm:       "my-swarm",
+		User:        "alice",
+	}
+
+	tests := []struct {
+		texts    map[string]string
+		expected string
+	}{
+		{nil, "Kocho: alice ran `kocho destroy my-swarm`, which failed"},
+		{map[string]string{"destroy": "{{.User}} destroyed {{.Swarm}}"}, "alice destroyed my-swarm"},
+		{map[string]string{"create": "{{.User}} created {{.Swarm}}", "default": "{{.Command}} {{.Result}}"}, "destroy failed"},
+	}
+
+	for _, test := range tests {
+		text, err := mustTemplates(t, test.texts).Render(event)
+		if err != nil {
+			t.Fatalf("Failed to render %v: %v", test.texts, err)
+		}
+		if text != test.expected {
+			t.Errorf("expected %q for %v, got %q", test.expected, test.texts, text)
+		}
+	}
+}
+
+func TestInvalidTemplates(t *testing.T) {
+	if _, err := NewTemplates(map[string]string{"create": "{{.User"}); !IsInvalidConfiguration(err) {
+		t.Fatalf("expected ErrInvalidConfiguration, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	// SignatureHeader holds the HMAC-SHA256 signature of the payload of webhook
+	// notifications, as "sha256=<hex digest>", if a secret is configured.
+	SignatureHeader = "X-Kocho-Signature"
+
+	webhookTimeout = 10 * time.Second
+)
+
+// WebhookConfiguration describes a configuration for posting events to an HTTP endpoint.
+type WebhookConfiguration struct {
+	URL     string            `mapstructure:"url"`     // URL is the endpoint to post events to.
+	Headers map[string]string `mapstructure:"headers"` // Headers are added to every request, e.g. for authentication.
+	Secret  string            `mapstructure:"secret"`  // Secret is the key to sign payloads with, if not empty.
+}
+
+// NewWebhookNotifier returns a WebhookNotifier for the given configuration,
+// rendering the text of events with the given templates.
+func NewWebhookNotifier(config WebhookConfiguration, templates *Templates) (*WebhookNotifier, error) {
+	if config.URL == "" {
+		return nil, errgo.WithCausef(nil, ErrInvalidConfiguration, "webhook URL must be set")
+	}
+	return &WebhookNotifier{
+		config:    config,
+		templates: templates,
+		client:    &http.Client{Timeout: webhookTimeout},
+	}, nil
+}
+
+// WebhookNotifier posts events as JSON to an HTTP endpoint.
+type WebhookNotifier struct {
+	config    WebhookConfiguration
+	templates *Templates
+	client    *http.Client
+}
+
+// webhookPayload is the event along with its rendered text, e.g. for chat tools.
+type webhookPayload struct {
+	Event
+	Text string `json:"text"`
+}
+
+// Notify posts the event to the endpoint of the webhook.
+func (w *WebhookNotifier) Notify(event Event) error {
+	text, err := w.templates.Render(event)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(webhookPayload{Event: event, Text: text})
+	if err != nil {
+		return errgo.Mask(err)
+	}
+
+	req, err := http.NewRequest("POST", w.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return errgo.WithCausef(err, ErrInvalidConfiguration, "invalid webhook URL %s", w.config.URL)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.config.Headers {
+		req.Header.Set(key, value)
+	}
+	if w.config.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+sign(w.config.Secret, payload))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errgo.Notef(err, "failed to post to webhook %s", w.config.URL)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errgo.Newf("webhook %s responded with %s", w.config.URL, resp.Status)
+	}
+	return nil
+}
+
+// sign returns the hex encoded HMAC-SHA256 of the payload with the given secret.
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// webhookRequest is a request received by the test server.
+type webhookRequest struct {
+	body   []byte
+	header http.Header
+	err    error
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	requests := make(chan webhookRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		requests <- webhookRequest{body: body, header: r.Header, err: err}
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(WebhookConfiguration{
+		URL:     server.URL,
+		Headers: map[string]string{"x-team": "infra"},
+		Secret:  "s3cret",
+	}, mustTemplates(t, map[string]string{"create": "{{.User}} created {{.Swarm}}"}))
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	event := NewEvent("create", []string{"kocho", "create", "my-swarm"}, "alice", "1.0.0", "abc")
+	event.Swarm = "my-swarm"
+	event.Success = true
+	if err := notifier.Notify(event); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+
+	request := <-requests
+	if request.err != nil {
+		t.Fatalf("Failed to read request: %v", request.err)
+	}
+	var received webhookPayload
+	if err := json.Unmarshal(request.body, &received); err != nil {
+		t.Fatalf("Invalid payload %s: %v", request.body, err)
+	}
+	if expected := "sha256=" + sign("s3cret", request.body); request.header.Get(SignatureHeader) != expected {
+		t.Errorf("expected signature %s, got %s", expected, request.header.Get(SignatureHeader))
+	}
+
+	if received.Command != "create" || received.CommandLine != "kocho create my-swarm" || received.Swarm != "my-swarm" || !received.Success || received.User != "alice" || received.Version != "1.0.0" {
+		t.Errorf("unexpected event received: %#v", received)
+	}
+	if received.Text != "alice created my-swarm" {
+		t.Errorf("expected rendered text, got %q", received.Text)
+	}
+	if header := request.header.Get("X-Team"); header != "infra" {
+		t.Errorf("expected configured header to be sent, got %q", header)
+	}
+}
+
+func TestWebhookNotifierFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(WebhookConfiguration{URL: server.URL}, mustTemplates(t, nil))
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+	if err := notifier.Notify(Event{}); err == nil {
+		t.Fatalf("expected error for failing webhook")
+	}
+}
+
+func TestNotifiersWithoutReceivers(t *testing.T) {
+	if err := (Notifiers{}).Notify(Event{}); !IsNotConfigured(err) {
+		t.Fatalf("expected ErrNotConfigured, got %v", err)
+	}
+}
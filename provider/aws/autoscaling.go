@@ -1,21 +1,22 @@
 package aws
 
 import (
-	"github.com/giantswarm/kocho/provider/aws/types"
+	"github.com/giantswarm/kocho/provider/aws/sdk"
 )
 
-func (aws AwsProvider) getInstances(autoScalingGroupName string) ([]types.Instance, error) {
+// getAutoScalingInstances returns the instances of the given auto scaling
+// group, indexed by their instance id.
+func (aws AwsProvider) getAutoScalingInstances(autoScalingGroupName string) (map[string]sdk.AutoScalingInstance, error) {
 	autoScalingGroup, err := aws.autoscaling.DescribeAutoScalingGroup(autoScalingGroupName)
-
-	var instanceIds []string
-	for _, i := range autoScalingGroup.Instances {
-		instanceIds = append(instanceIds, i.InstanceId)
-	}
-	instances, err := aws.ec2.DescribeInstances(instanceIds)
 	if err != nil {
 		return nil, err
 	}
 
+	instances := make(map[string]sdk.AutoScalingInstance, len(autoScalingGroup.Instances))
+	for _, i := range autoScalingGroup.Instances {
+		instances[i.InstanceId] = i
+	}
+
 	return instances, nil
 }
 
@@ -2,6 +2,8 @@
 package aws
 
 import (
+	"strings"
+
 	"github.com/giantswarm/kocho/provider"
 	"github.com/giantswarm/kocho/provider/aws/sdk"
 	"github.com/giantswarm/kocho/provider/aws/types"
@@ -10,32 +12,69 @@ import (
 	"github.com/juju/errgo"
 )
 
-// AwsProvider represents a Provider running on AWS.
+// AwsProvider represents a Provider running on AWS in a single region.
 type AwsProvider struct {
+	region string
+
 	autoscaling    *sdk.AutoScaling
 	cloudformation *sdk.CloudFormation
 	ec2            *sdk.EC2
 	elb            *sdk.ELB
+	s3             *sdk.S3
 }
 
 const (
 	swarmStandaloneTemplate = "standalone"
 	swarmSecondaryTemplate  = "secondary"
 	swarmPrimaryTemplate    = "primary"
+
+	// protectedTag mirrors the termination protection of the stack of swarms
+	// protected against deletion.
+	protectedTag = "Protected"
+
+	// AllRegions can be given to Init to span all regions available to the account.
+	AllRegions = "all"
 )
 
-// Init initialises the AWS Provider.
-func Init() provider.Provider {
+// Init initialises the AWS Provider for the given regions. Without regions,
+// the region of the session is used. Otherwise swarms are listed and looked
+// up in the given regions, but created in the region of the session.
+func Init(regions ...string) (provider.Provider, error) {
+	if len(regions) == 1 && regions[0] == AllRegions {
+		var err error
+		regions, err = sdk.NewEC2("").DescribeRegions()
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+	}
+
+	if len(regions) == 0 {
+		return newAwsProvider(""), nil
+	}
+	if len(regions) == 1 && regions[0] == sdk.DefaultSessionProvider.ConfigRegion {
+		return newAwsProvider(""), nil
+	}
+	return newMultiRegionProvider(regions), nil
+}
+
+func newAwsProvider(region string) AwsProvider {
+	if region == "" {
+		region = sdk.DefaultSessionProvider.ConfigRegion
+	}
+
 	return AwsProvider{
-		autoscaling:    sdk.NewAutoScaling(),
-		cloudformation: sdk.NewCloudFormation(),
-		ec2:            sdk.NewEC2(),
-		elb:            sdk.NewELB(),
+		region:         region,
+		autoscaling:    sdk.NewAutoScaling(region),
+		cloudformation: sdk.NewCloudFormation(region),
+		ec2:            sdk.NewEC2(region),
+		elb:            sdk.NewELB(region),
+		s3:             sdk.NewS3(region),
 	}
 }
 
-// GetSwarms returns a list of all the Swarms running on AWS.
-func (aws AwsProvider) GetSwarms() ([]provider.ProviderSwarm, error) {
+// GetSwarms returns a list of all the Swarms running on AWS. Unless
+// includeUnmanaged is set, stacks not created by kocho are skipped.
+func (aws AwsProvider) GetSwarms(includeUnmanaged bool) ([]provider.ProviderSwarm, error) {
 	stacks, err := aws.cloudformation.DescribeStacks()
 	if err != nil {
 		return nil, errgo.Mask(err)
@@ -43,13 +82,20 @@ func (aws AwsProvider) GetSwarms() ([]provider.ProviderSwarm, error) {
 
 	var swarms []provider.ProviderSwarm
 	for _, stack := range stacks.Stacks {
+		if !includeUnmanaged && !isManagedStack(stack.Tags) {
+			continue
+		}
+
 		// for now ignore if there is no stack type yet
 		swarmType, _ := findSwarmType(stack.Tags)
 
 		swarms = append(swarms, AwsSwarm{
+			Id:           stack.Id,
 			Name:         stack.Name,
 			Type:         swarmType,
 			CreationTime: stack.CreationTime,
+			Tags:         stack.Tags,
+			Protected:    isProtectedStack(stack),
 			Provider:     aws,
 		})
 	}
@@ -71,7 +117,10 @@ func (aws AwsProvider) GetSwarm(name string) (provider.ProviderSwarm, error) {
 		return nil, errgo.Mask(err)
 	}
 
+	swarm.Id = stack.Id
 	swarm.CreationTime = stack.CreationTime
+	swarm.Tags = stack.Tags
+	swarm.Protected = isProtectedStack(*stack)
 
 	// for now ignore if there is no stack type yet
 	swarm.Type, _ = findSwarmType(stack.Tags)
@@ -79,10 +128,45 @@ func (aws AwsProvider) GetSwarm(name string) (provider.ProviderSwarm, error) {
 	return swarm, nil
 }
 
-// CreateSwarm creates and returns a Swarm, given a name, CreateFlags and cloud config text.
-func (aws AwsProvider) CreateSwarm(name string, flags swarmtypes.CreateFlags, cloudconfigText string) (provider.ProviderSwarm, error) {
+// CreateSwarm creates and returns a Swarm, given a name, the Spec to create it with and cloud config text.
+// The spec is recorded in the tags of the stack.
+func (aws AwsProvider) CreateSwarm(name string, spec swarmtypes.Spec, cloudconfigText string) (provider.ProviderSwarm, error) {
+	cloudformationTmpl, parametersTmpl, img, err := aws.renderStack(name, spec, cloudconfigText)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	templateURL, cleanup, err := aws.stageTemplate(name, cloudformationTmpl, spec.AWSCreateFlags)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	defer cleanup()
+
+	tags, err := stackTags(spec, img)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	_, err = aws.cloudformation.CreateStack(name, spec.Type,
+		cloudformationTmpl,
+		templateURL,
+		parametersTmpl,
+		tags,
+	)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	return aws.GetSwarm(name)
+}
+
+// renderStack renders the CloudFormation template and parameters files for a
+// swarm with the given spec, and returns their paths along with the image the
+// swarm is created with.
+func (aws AwsProvider) renderStack(name string, spec swarmtypes.Spec, cloudconfigText string) (string, string, image, error) {
+	flags := spec.CreateFlags
 	if flags.AWSCreateFlags == nil {
-		return nil, errgo.Newf("invalid arguments to create the swarm: AWSCreateFlags must be provided")
+		return "", "", image{}, errgo.Newf("invalid arguments to create the swarm: AWSCreateFlags must be provided")
 	}
 
 	var (
@@ -91,50 +175,135 @@ func (aws AwsProvider) CreateSwarm(name string, flags swarmtypes.CreateFlags, cl
 		err                error
 	)
 
+	if flags.UseMixedInstances() && flags.Type == swarmPrimaryTemplate {
+		return "", "", image{}, errgo.Newf("invalid arguments to create the swarm: spot and mixed instances are not supported for primary swarms")
+	}
+
+	awsFlags, err := aws.resolvePlacement(flags.AWSCreateFlags)
+	if err != nil {
+		return "", "", image{}, errgo.Mask(err)
+	}
+
+	img, err := aws.resolveImage(flags.ImageURI)
+	if err != nil {
+		return "", "", image{}, errgo.Mask(err)
+	}
+	if flags.UseIgnition && !img.supportsIgnition() {
+		return "", "", image{}, errgo.Newf("invalid arguments to create the swarm: ignition requires CoreOS %s or later, but image %s is CoreOS %s", minIgnitionVersion, img.Id, img.Version)
+	}
+
 	switch flags.Type {
 	case swarmPrimaryTemplate:
-		cloudformationTmpl, err = createPrimaryCloudformationTemplate(name, flags.ClusterSize, flags.TemplateDir, flags.AWSCreateFlags.VPCCIDR)
+		cloudformationTmpl, err = createPrimaryCloudformationTemplate(name, flags.ClusterSize, len(awsFlags.Subnets()), flags.TemplateDir, awsFlags.VPCCIDR)
 		if err != nil {
-			return nil, errgo.Mask(err)
+			return "", "", image{}, errgo.Mask(err)
 		}
-		parametersTmpl, err = createPrimaryParametersTemplate(flags.ImageURI, cloudconfigText, flags.MachineType, flags.ClusterSize, flags.TemplateDir, flags.AWSCreateFlags)
+		parametersTmpl, err = createPrimaryParametersTemplate(img.Id, cloudconfigText, flags.MachineType, flags.ClusterSize, flags.TemplateDir, awsFlags)
 		if err != nil {
-			return nil, errgo.Mask(err)
+			return "", "", image{}, errgo.Mask(err)
 		}
 	case swarmSecondaryTemplate:
-		cloudformationTmpl, err = createSecondaryCloudformationTemplate(flags.TemplateDir, flags.AWSCreateFlags.VPCCIDR)
+		cloudformationTmpl, err = createSecondaryCloudformationTemplate(flags.TemplateDir, awsFlags.VPCCIDR, newMixedInstances(flags))
 		if err != nil {
-			return nil, errgo.Mask(err)
+			return "", "", image{}, errgo.Mask(err)
 		}
-		parametersTmpl, err = createSecondaryParametersTemplate(flags.ImageURI, cloudconfigText, flags.MachineType, flags.CertificateURI, flags.ClusterSize, flags.TemplateDir, flags.AWSCreateFlags)
+		parametersTmpl, err = createSecondaryParametersTemplate(img.Id, cloudconfigText, flags.MachineType, flags.CertificateURI, flags.ClusterSize, flags.TemplateDir, awsFlags)
 		if err != nil {
-			return nil, errgo.Mask(err)
+			return "", "", image{}, errgo.Mask(err)
 		}
 	case swarmStandaloneTemplate:
-		cloudformationTmpl, err = createStandaloneCloudformationTemplate(flags.TemplateDir, flags.AWSCreateFlags.VPCCIDR)
+		cloudformationTmpl, err = createStandaloneCloudformationTemplate(flags.TemplateDir, awsFlags.VPCCIDR, newMixedInstances(flags))
 		if err != nil {
-			return nil, errgo.Mask(err)
+			return "", "", image{}, errgo.Mask(err)
 		}
-		parametersTmpl, err = createStandaloneParametersTemplate(flags.ImageURI, cloudconfigText, flags.MachineType, flags.CertificateURI, flags.ClusterSize, flags.TemplateDir, flags.AWSCreateFlags)
+		parametersTmpl, err = createStandaloneParametersTemplate(img.Id, cloudconfigText, flags.MachineType, flags.CertificateURI, flags.ClusterSize, flags.TemplateDir, awsFlags)
 		if err != nil {
-			return nil, errgo.Mask(err)
+			return "", "", image{}, errgo.Mask(err)
 		}
 	}
 
-	_, err = aws.cloudformation.CreateStack(name, flags.Type,
-		cloudformationTmpl,
-		parametersTmpl,
-	)
+	return cloudformationTmpl, parametersTmpl, img, nil
+}
+
+// resolvePlacement validates that all given subnets belong to the VPC and lie
+// in the given availability zones. It returns a copy of the flags with
+// normalized subnet and availability zone lists, with the zones listed per
+// subnet, so the machines of primary swarms can select matching pairs.
+func (aws AwsProvider) resolvePlacement(flags *swarmtypes.AWSCreateFlags) (*swarmtypes.AWSCreateFlags, error) {
+	subnetIds := flags.Subnets()
+	if len(subnetIds) == 0 {
+		return nil, errgo.Newf("at least one subnet must be provided")
+	}
+
+	subnets, err := aws.ec2.DescribeSubnets(subnetIds)
 	if err != nil {
 		return nil, errgo.Mask(err)
 	}
+	return placeSubnets(flags, subnets)
+}
 
-	return aws.GetSwarm(name)
+// placeSubnets returns a copy of the flags placing the swarm in the described
+// subnets. The load balancers of the swarm are given all subnets, and accept
+// only one subnet per availability zone, so subnets sharing a zone are rejected.
+func placeSubnets(flags *swarmtypes.AWSCreateFlags, subnets []sdk.Subnet) (*swarmtypes.AWSCreateFlags, error) {
+	allowedZones := flags.AvailabilityZones()
+
+	var subnetIds, zones []string
+	zoneSubnets := map[string]string{}
+	for _, subnet := range subnets {
+		if flags.VPC != "" && subnet.VpcId != flags.VPC {
+			return nil, errgo.Newf("subnet %s belongs to VPC %s, not %s", subnet.SubnetId, subnet.VpcId, flags.VPC)
+		}
+		if len(allowedZones) > 0 && !contains(allowedZones, subnet.AvailabilityZone) {
+			return nil, errgo.Newf("subnet %s is in availability zone %s, which is not one of %s", subnet.SubnetId, subnet.AvailabilityZone, flags.AvailabilityZone)
+		}
+		if other, ok := zoneSubnets[subnet.AvailabilityZone]; ok {
+			return nil, errgo.Newf("subnets %s and %s are both in availability zone %s, but load balancers accept only one subnet per availability zone", other, subnet.SubnetId, subnet.AvailabilityZone)
+		}
+		zoneSubnets[subnet.AvailabilityZone] = subnet.SubnetId
+
+		subnetIds = append(subnetIds, subnet.SubnetId)
+		zones = append(zones, subnet.AvailabilityZone)
+	}
+
+	resolved := *flags
+	resolved.Subnet = strings.Join(subnetIds, ",")
+	resolved.AvailabilityZone = strings.Join(zones, ",")
+	return &resolved, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isManagedStack returns true if the stack was created by kocho. Stacks
+// created by older versions of kocho are only marked with their type.
+func isManagedStack(tags []types.Tag) bool {
+	for _, tag := range tags {
+		if tag.Key == sdk.ManagedByTag && tag.Value == sdk.ManagedByValue {
+			return true
+		}
+		if tag.Key == sdk.StackTypeTag {
+			return true
+		}
+	}
+	return false
+}
+
+// isProtectedStack returns true if the stack has termination protection
+// enabled. The tag of protected stacks may be outdated, so it is ignored.
+func isProtectedStack(stack sdk.Stack) bool {
+	return stack.TerminationProtection
 }
 
 func findSwarmType(tags []types.Tag) (string, error) {
 	for _, tag := range tags {
-		if tag.Key == "StackType" {
+		if tag.Key == sdk.StackTypeTag {
 			return tag.Value, nil
 		}
 	}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/giantswarm/kocho/provider/aws/sdk"
+	"github.com/giantswarm/kocho/provider/aws/types"
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+// TestIsManagedStack checks that stacks created by current and older versions
+// of kocho are detected, and other stacks are not.
+func TestIsManagedStack(t *testing.T) {
+	testCases := []struct {
+		Tags     []types.Tag
+		Expected bool
+	}{
+		{[]types.Tag{}, false},
+		{[]types.Tag{{Key: "Name", Value: "some-stack"}}, false},
+		{[]types.Tag{{Key: "ManagedBy", Value: "terraform"}}, false},
+		{[]types.Tag{{Key: "ManagedBy", Value: "kocho"}}, true},
+		{[]types.Tag{{Key: "StackType", Value: "primary"}}, true},
+		{[]types.Tag{{Key: "StackType", Value: "standalone"}, {Key: "ManagedBy", Value: "kocho"}}, true},
+	}
+
+	for index, testCase := range testCases {
+		if managed := isManagedStack(testCase.Tags); managed != testCase.Expected {
+			t.Fatalf("test %d: expected stack with tags %v to be managed=%v, got %v", index, testCase.Tags, testCase.Expected, managed)
+		}
+	}
+}
+
+// TestPlaceSubnets checks that subnets are validated, and that subnets sharing
+// an availability zone are rejected, as load balancers don't accept them.
+func TestPlaceSubnets(t *testing.T) {
+	subnets := []sdk.Subnet{
+		{SubnetId: "subnet-a", VpcId: "vpc-1", AvailabilityZone: "eu-west-1a"},
+		{SubnetId: "subnet-b", VpcId: "vpc-1", AvailabilityZone: "eu-west-1b"},
+	}
+
+	resolved, err := placeSubnets(&swarmtypes.AWSCreateFlags{VPC: "vpc-1"}, subnets)
+	if err != nil {
+		t.Fatalf("Failed to place subnets: %v", err)
+	}
+	if resolved.Subnet != "subnet-a,subnet-b" || resolved.AvailabilityZone != "eu-west-1a,eu-west-1b" {
+		t.Fatalf("expected subnets with their zones, got %s in %s", resolved.Subnet, resolved.AvailabilityZone)
+	}
+
+	if _, err := placeSubnets(&swarmtypes.AWSCreateFlags{VPC: "vpc-2"}, subnets); err == nil {
+		t.Fatalf("expected subnets of another VPC to be rejected")
+	}
+	if _, err := placeSubnets(&swarmtypes.AWSCreateFlags{AvailabilityZone: "eu-west-1a"}, subnets); err == nil {
+		t.Fatalf("expected subnets outside of the given zones to be rejected")
+	}
+
+	shared := append(subnets, sdk.Subnet{SubnetId: "subnet-c", VpcId: "vpc-1", AvailabilityZone: "eu-west-1a"})
+	if _, err := placeSubnets(&swarmtypes.AWSCreateFlags{}, shared); err == nil {
+		t.Fatalf("expected subnets sharing an availability zone to be rejected")
+	}
+}
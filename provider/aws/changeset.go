@@ -0,0 +1,102 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+// primaryMachinePattern matches the logical IDs of the machines of primary swarms.
+var primaryMachinePattern = regexp.MustCompile(`^Machine[0-9]+$`)
+
+// CreateChangeSet creates a change set updating the stack of the swarm to the
+// given spec and cloud config text. The returned change set has no ID if the
+// stack is already up to date.
+func (s AwsSwarm) CreateChangeSet(spec swarmtypes.Spec, cloudconfigText string) (*swarmtypes.ChangeSet, error) {
+	templateFile, parametersFile, img, err := s.Provider.renderStack(s.Name, spec, cloudconfigText)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	stack, err := s.Provider.cloudformation.DescribeStack(s.Name)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	// Change sets replace all tags of the stack, so keep the ones not describing the spec
+	tags, err := stackTags(spec, img)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	for _, tag := range stack.Tags {
+		if _, ok := tags[tag.Key]; !ok && !isImageOrSpecTag(tag.Key) {
+			tags[tag.Key] = tag.Value
+		}
+	}
+
+	templateURL, cleanup, err := s.Provider.stageTemplate(s.Name, templateFile, spec.AWSCreateFlags)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	defer cleanup()
+
+	changeSetName := fmt.Sprintf("kocho-%s", time.Now().UTC().Format("20060102-150405"))
+	awsChangeSet, err := s.Provider.cloudformation.CreateChangeSet(s.Name, changeSetName, templateFile, templateURL, parametersFile, stack.Capabilities, tags)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	changeSet := &swarmtypes.ChangeSet{}
+	if len(awsChangeSet.Changes) > 0 {
+		changeSet.Id = awsChangeSet.Id
+	}
+	for _, change := range awsChangeSet.Changes {
+		changeSet.Changes = append(changeSet.Changes, swarmtypes.ResourceChange{
+			Action:      change.Action,
+			Type:        change.Type,
+			LogicalId:   change.LogicalId,
+			PhysicalId:  change.PhysicalId,
+			Replacement: change.Replacement,
+
+			ReplacesQuorumMember: s.Type == swarmPrimaryTemplate && replacesMachine(change.Action, change.Replacement, change.LogicalId),
+		})
+	}
+	return changeSet, nil
+}
+
+// ExecuteChangeSet starts the update of the swarm with the given change set.
+func (s AwsSwarm) ExecuteChangeSet(changeSet *swarmtypes.ChangeSet) error {
+	if changeSet.Id == "" {
+		return nil
+	}
+	return errgo.Mask(s.Provider.cloudformation.ExecuteChangeSet(changeSet.Id))
+}
+
+// DeleteChangeSet deletes the given change set without executing it.
+func (s AwsSwarm) DeleteChangeSet(changeSet *swarmtypes.ChangeSet) error {
+	if changeSet.Id == "" {
+		return nil
+	}
+	return errgo.Mask(s.Provider.cloudformation.DeleteChangeSet(changeSet.Id))
+}
+
+// replacesMachine returns true if the change removes or replaces a machine of a primary swarm.
+func replacesMachine(action, replacement, logicalId string) bool {
+	if !primaryMachinePattern.MatchString(logicalId) {
+		return false
+	}
+	return action == "Remove" || (action == "Modify" && replacement != "False")
+}
+
+func isImageOrSpecTag(key string) bool {
+	switch key {
+	case imageIdTag, coreOSChannelTag, coreOSVersionTag:
+		return true
+	}
+	return strings.HasPrefix(key, specTagPrefix)
+}
@@ -0,0 +1,26 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestReplacesMachine(t *testing.T) {
+	testCases := []struct {
+		Action, Replacement, LogicalId string
+		Expected                       bool
+	}{
+		{"Modify", "True", "Machine0", true},
+		{"Modify", "Conditional", "Machine12", true},
+		{"Modify", "False", "Machine0", false},
+		{"Remove", "", "Machine4", true},
+		{"Add", "", "Machine5", false},
+		{"Modify", "True", "ElasticLoadBalancerPrivate", false},
+		{"Modify", "True", "MachineSecurityGroup", false},
+	}
+
+	for _, testCase := range testCases {
+		if actual := replacesMachine(testCase.Action, testCase.Replacement, testCase.LogicalId); actual != testCase.Expected {
+			t.Errorf("expected %s of %s with replacement '%s' to return %v, got %v", testCase.Action, testCase.LogicalId, testCase.Replacement, testCase.Expected, actual)
+		}
+	}
+}
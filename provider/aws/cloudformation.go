@@ -10,6 +10,8 @@ import (
 	"text/template"
 
 	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/swarm/types"
 )
 
 const (
@@ -24,20 +26,45 @@ const (
 
 type primaryCloudformation struct {
 	Name              string
-	Machines          []int // the index of the machines to iterate over in the template
+	Machines          []primaryMachine // the machines to iterate over in the template
 	MachineReferences string
 	Type              string
 	VPCCIDR           string
 }
 
+// primaryMachine describes a single machine of a primary swarm. Zone is the
+// index into the AZ and MachineSubnet lists the machine is placed in.
+type primaryMachine struct {
+	Index int
+	Zone  int
+}
+
 type secondaryCloudformation struct {
-	Type    string
-	VPCCIDR string
+	Type           string
+	VPCCIDR        string
+	MixedInstances mixedInstances
 }
 
 type standaloneCloudformation struct {
-	Type    string
-	VPCCIDR string
+	Type           string
+	VPCCIDR        string
+	MixedInstances mixedInstances
+}
+
+// mixedInstances describes the mixed instances policy of an autoscaling
+// group. If not enabled, a plain launch configuration is used.
+type mixedInstances struct {
+	Enabled      bool
+	MachineTypes []string
+	SpotMaxPrice string
+}
+
+func newMixedInstances(flags swarmtypes.CreateFlags) mixedInstances {
+	return mixedInstances{
+		Enabled:      flags.UseMixedInstances(),
+		MachineTypes: flags.MachineTypeList(),
+		SpotMaxPrice: flags.SpotMaxPrice,
+	}
 }
 
 type machineReference struct {
@@ -56,38 +83,52 @@ func createMachineReferences(clusterSize int) string {
 	return string(jsonList)
 }
 
-func createPrimaryCloudformationTemplate(name string, clusterSize int, templateDir string, vpccidr string) (string, error) {
-	machineIds := make([]int, clusterSize)
-	for id, _ := range machineIds {
-		machineIds[id] = id
+// distributeMachines spreads the given number of machines round-robin over
+// the given number of zones.
+func distributeMachines(clusterSize, zoneCount int) []primaryMachine {
+	if zoneCount < 1 {
+		zoneCount = 1
+	}
+
+	machines := make([]primaryMachine, clusterSize)
+	for id := range machines {
+		machines[id] = primaryMachine{
+			Index: id,
+			Zone:  id % zoneCount,
+		}
 	}
+	return machines
+}
 
+func createPrimaryCloudformationTemplate(name string, clusterSize, zoneCount int, templateDir string, vpccidr string) (string, error) {
 	cloudFormationTemplatePath := path.Join(templateDir, primaryCloudFormationTemplateName)
 
 	return parseCloudformationTemplate(cloudFormationTemplatePath, primaryCloudformation{
 		Name:              name,
-		Machines:          machineIds,
+		Machines:          distributeMachines(clusterSize, zoneCount),
 		MachineReferences: createMachineReferences(clusterSize),
 		Type:              "primary",
 		VPCCIDR:           vpccidr,
 	})
 }
 
-func createSecondaryCloudformationTemplate(templateDir string, vpccidr string) (string, error) {
+func createSecondaryCloudformationTemplate(templateDir string, vpccidr string, mixed mixedInstances) (string, error) {
 	cloudFormationTemplatePath := path.Join(templateDir, secondaryCloudFormationTemplateName)
 
 	return parseCloudformationTemplate(cloudFormationTemplatePath, secondaryCloudformation{
-		Type:    "secondary",
-		VPCCIDR: vpccidr,
+		Type:           "secondary",
+		VPCCIDR:        vpccidr,
+		MixedInstances: mixed,
 	})
 }
 
-func createStandaloneCloudformationTemplate(templateDir string, vpccidr string) (string, error) {
+func createStandaloneCloudformationTemplate(templateDir string, vpccidr string, mixed mixedInstances) (string, error) {
 	cloudFormationTemplatePath := path.Join(templateDir, standaloneCloudFormationTemplateName)
 
 	return parseCloudformationTemplate(cloudFormationTemplatePath, standaloneCloudformation{
-		Type:    "standalone",
-		VPCCIDR: vpccidr,
+		Type:           "standalone",
+		VPCCIDR:        vpccidr,
+		MixedInstances: mixed,
 	})
 }
 
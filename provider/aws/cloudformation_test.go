@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDistributeMachines checks that the machines of a primary swarm are
+// spread round-robin over the available zones.
+func TestDistributeMachines(t *testing.T) {
+	testCases := []struct {
+		ClusterSize int
+		ZoneCount   int
+		Zones       []int
+	}{
+		{3, 1, []int{0, 0, 0}},
+		{3, 0, []int{0, 0, 0}},
+		{3, 2, []int{0, 1, 0}},
+		{3, 3, []int{0, 1, 2}},
+		{5, 3, []int{0, 1, 2, 0, 1}},
+	}
+
+	for _, testCase := range testCases {
+		machines := distributeMachines(testCase.ClusterSize, testCase.ZoneCount)
+
+		zones := []int{}
+		for index, machine := range machines {
+			if machine.Index != index {
+				t.Fatalf("expected machine %d to have index %d, got %d", index, index, machine.Index)
+			}
+			zones = append(zones, machine.Zone)
+		}
+
+		if !reflect.DeepEqual(testCase.Zones, zones) {
+			t.Fatalf("expected %d machines over %d zones to be placed in %v, got %v", testCase.ClusterSize, testCase.ZoneCount, testCase.Zones, zones)
+		}
+	}
+}
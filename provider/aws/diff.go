@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+// cloudConfigParameter is the stack parameter holding the base64 encoded cloud config.
+const cloudConfigParameter = "CloudConfig"
+
+// Render returns the template and parameters the swarm would be created with,
+// given the spec and cloud config text.
+func (s AwsSwarm) Render(spec swarmtypes.Spec, cloudconfigText string) (*swarmtypes.StackDocuments, error) {
+	templateFile, parametersFile, _, err := s.Provider.renderStack(s.Name, spec, cloudconfigText)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	templateBody, err := ioutil.ReadFile(templateFile)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	parametersBody, err := ioutil.ReadFile(parametersFile)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	var params []struct {
+		ParameterKey   string
+		ParameterValue string
+	}
+	if err := json.Unmarshal(parametersBody, &params); err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	parameters := map[string]string{}
+	for _, p := range params {
+		parameters[p.ParameterKey] = p.ParameterValue
+	}
+
+	return newStackDocuments(string(templateBody), parameters)
+}
+
+// GetDeployed returns the template and parameters of the running stack of the swarm.
+func (s AwsSwarm) GetDeployed() (*swarmtypes.StackDocuments, error) {
+	templateBody, err := s.Provider.cloudformation.GetTemplate(s.Name)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	stack, err := s.Provider.cloudformation.DescribeStack(s.Name)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	return newStackDocuments(templateBody, stack.Parameters)
+}
+
+// DetectDrift runs CloudFormation drift detection on the stack of the swarm,
+// and returns the resources that were modified outside of CloudFormation.
+func (s AwsSwarm) DetectDrift() ([]swarmtypes.ResourceDrift, error) {
+	awsDrifts, err := s.Provider.cloudformation.DetectStackDrift(s.Name)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	var drifts []swarmtypes.ResourceDrift
+	for _, drift := range awsDrifts {
+		drifts = append(drifts, swarmtypes.ResourceDrift{
+			Type:       drift.Type,
+			LogicalId:  drift.LogicalId,
+			PhysicalId: drift.PhysicalId,
+			Status:     drift.Status,
+		})
+	}
+	return drifts, nil
+}
+
+// newStackDocuments normalizes the template, and decodes the cloud config
+// from the parameters, so both can be compared line by line.
+func newStackDocuments(templateBody string, parameters map[string]string) (*swarmtypes.StackDocuments, error) {
+	template, err := normalizeJSON(templateBody)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	docs := &swarmtypes.StackDocuments{
+		Template:   template,
+		Parameters: map[string]string{},
+	}
+	for key, value := range parameters {
+		if key == cloudConfigParameter {
+			config, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, errgo.Mask(err)
+			}
+			docs.Config = string(config)
+			continue
+		}
+		docs.Parameters[key] = value
+	}
+	return docs, nil
+}
+
+// normalizeJSON reformats the given JSON document with sorted keys and
+// consistent indentation.
+func normalizeJSON(document string) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		return "", errgo.Mask(err)
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestNewStackDocuments(t *testing.T) {
+	deployed, err := newStackDocuments(`{"Resources":{"B":{},"A":{"Type":"AWS::EC2::Instance"}}}`, map[string]string{
+		"CloudConfig":  base64.StdEncoding.EncodeToString([]byte("#cloud-config\n")),
+		"InstanceType": "m3.large",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rendered, err := newStackDocuments("{\n\t\"Resources\": {\n\t\t\"A\": {\"Type\": \"AWS::EC2::Instance\"},\n\t\t\"B\": {}\n\t}\n}", map[string]string{
+		"CloudConfig":  base64.StdEncoding.EncodeToString([]byte("#cloud-config\n")),
+		"InstanceType": "m3.large",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if deployed.Template != rendered.Template {
+		t.Fatalf("expected templates differing in formatting only to be equal, got:\n%s\n%s", deployed.Template, rendered.Template)
+	}
+	if deployed.Config != "#cloud-config\n" {
+		t.Fatalf("expected cloud config to be decoded, got '%s'", deployed.Config)
+	}
+	if _, ok := deployed.Parameters["CloudConfig"]; ok {
+		t.Fatalf("expected cloud config to be removed from the parameters")
+	}
+	if deployed.Parameters["InstanceType"] != "m3.large" {
+		t.Fatalf("expected parameters to be kept, got %v", deployed.Parameters)
+	}
+}
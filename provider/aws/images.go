@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	// coreOSImagePrefix marks image URIs to resolve, e.g. coreos:stable or coreos:beta:1010.3.0
+	coreOSImagePrefix = "coreos:"
+
+	// coreOSOwnerId is the AWS account publishing the official CoreOS images.
+	coreOSOwnerId = "595879546273"
+
+	// minIgnitionVersion is the first CoreOS stable release that ships with ignition.
+	minIgnitionVersion = "1010.5.0"
+
+	// Tags recording the image a stack was created with.
+	imageIdTag       = "ImageId"
+	coreOSChannelTag = "CoreOSChannel"
+	coreOSVersionTag = "CoreOSVersion"
+)
+
+var coreOSChannels = []string{"stable", "beta", "alpha"}
+
+// image describes the image a swarm is created with.
+type image struct {
+	Id string
+
+	// Channel and Version are only known for CoreOS images.
+	Channel string
+	Version string
+}
+
+// resolveImage returns the image to use for the given image URI. This is
+// either an AMI ID, or coreos:<channel>[:<version>] to look up the matching
+// CoreOS image in the region of the provider.
+func (aws AwsProvider) resolveImage(imageURI string) (image, error) {
+	if !strings.HasPrefix(imageURI, coreOSImagePrefix) {
+		return aws.describeImage(imageURI)
+	}
+
+	channel, version, err := parseCoreOSImageURI(imageURI)
+	if err != nil {
+		return image{}, errgo.Mask(err)
+	}
+
+	namePattern := coreOSImageName(channel, version)
+	if version == "" {
+		namePattern = coreOSImageName(channel, "*")
+	}
+
+	images, err := aws.ec2.FindImages(coreOSOwnerId, namePattern)
+	if err != nil {
+		return image{}, errgo.Mask(err)
+	}
+
+	var found image
+	for _, i := range images {
+		_, imageVersion, ok := parseCoreOSImageName(i.Name)
+		if !ok {
+			continue
+		}
+		if found.Id == "" || compareVersions(imageVersion, found.Version) > 0 {
+			found = image{Id: i.ImageId, Channel: channel, Version: imageVersion}
+		}
+	}
+
+	if found.Id == "" {
+		return image{}, errgo.Newf("no CoreOS %s image found for %s in region %s", channel, imageURI, aws.region)
+	}
+	return found, nil
+}
+
+// describeImage returns the image with the given AMI ID. If it is an official
+// CoreOS image, its channel and version are detected.
+func (aws AwsProvider) describeImage(imageId string) (image, error) {
+	i, err := aws.ec2.DescribeImage(imageId)
+	if err != nil {
+		return image{}, errgo.Mask(err)
+	}
+
+	result := image{Id: i.ImageId}
+	if i.OwnerId == coreOSOwnerId {
+		if channel, version, ok := parseCoreOSImageName(i.Name); ok {
+			result.Channel = channel
+			result.Version = version
+		}
+	}
+	return result, nil
+}
+
+// supportsIgnition returns false if the image is known to be too old for ignition.
+func (i image) supportsIgnition() bool {
+	return i.Version == "" || compareVersions(i.Version, minIgnitionVersion) >= 0
+}
+
+// imageTags returns the stack tags recording the given image.
+func imageTags(i image) map[string]string {
+	tags := map[string]string{imageIdTag: i.Id}
+	if i.Version != "" {
+		tags[coreOSChannelTag] = i.Channel
+		tags[coreOSVersionTag] = i.Version
+	}
+	return tags
+}
+
+// parseCoreOSImageURI splits coreos:<channel>[:<version>] into channel and version.
+func parseCoreOSImageURI(imageURI string) (string, string, error) {
+	parts := strings.Split(strings.TrimPrefix(imageURI, coreOSImagePrefix), ":")
+	if len(parts) > 2 {
+		return "", "", errgo.Newf("invalid image %s, expected coreos:<channel>[:<version>]", imageURI)
+	}
+
+	channel := parts[0]
+	if !contains(coreOSChannels, channel) {
+		return "", "", errgo.Newf("invalid CoreOS channel %s, expected one of %s", channel, strings.Join(coreOSChannels, ", "))
+	}
+
+	if len(parts) == 1 {
+		return channel, "", nil
+	}
+	return channel, parts[1], nil
+}
+
+func coreOSImageName(channel, version string) string {
+	return fmt.Sprintf("CoreOS-%s-%s-hvm", channel, version)
+}
+
+// parseCoreOSImageName extracts channel and version from the name of an
+// official CoreOS image, e.g. CoreOS-stable-1010.5.0-hvm.
+func parseCoreOSImageName(name string) (string, string, bool) {
+	parts := strings.Split(name, "-")
+	if len(parts) != 4 || parts[0] != "CoreOS" || parts[3] != "hvm" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// compareVersions compares two dotted version numbers, returning -1, 0 or 1.
+// Non-numeric parts compare as 0.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+
+		if aNum < bNum {
+			return -1
+		}
+		if aNum > bNum {
+			return 1
+		}
+	}
+	return 0
+}
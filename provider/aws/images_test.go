@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestParseCoreOSImageURI(t *testing.T) {
+	testCases := []struct {
+		ImageURI string
+		Channel  string
+		Version  string
+		Valid    bool
+	}{
+		{"coreos:stable", "stable", "", true},
+		{"coreos:beta:1010.3.0", "beta", "1010.3.0", true},
+		{"coreos:alpha", "alpha", "", true},
+		{"coreos:nightly", "", "", false},
+		{"coreos:stable:1010.5.0:hvm", "", "", false},
+	}
+
+	for _, testCase := range testCases {
+		channel, version, err := parseCoreOSImageURI(testCase.ImageURI)
+		if testCase.Valid != (err == nil) {
+			t.Fatalf("expected %s to be valid=%v, got error: %v", testCase.ImageURI, testCase.Valid, err)
+		}
+		if channel != testCase.Channel || version != testCase.Version {
+			t.Fatalf("expected %s to resolve to channel '%s' and version '%s', got '%s' and '%s'", testCase.ImageURI, testCase.Channel, testCase.Version, channel, version)
+		}
+	}
+}
+
+func TestParseCoreOSImageName(t *testing.T) {
+	channel, version, ok := parseCoreOSImageName("CoreOS-stable-1010.5.0-hvm")
+	if !ok || channel != "stable" || version != "1010.5.0" {
+		t.Fatalf("expected stable 1010.5.0, got '%s' '%s' %v", channel, version, ok)
+	}
+
+	if _, _, ok := parseCoreOSImageName("ubuntu-xenial-16.04-amd64-server"); ok {
+		t.Fatalf("expected non CoreOS image name to be rejected")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		A, B     string
+		Expected int
+	}{
+		{"681.2.0", "1010.5.0", -1},
+		{"1010.5.0", "1010.5.0", 0},
+		{"1068.8.0", "1010.5.0", 1},
+		{"1010.10.0", "1010.5.0", 1},
+		{"1010.5", "1010.5.0", 0},
+	}
+
+	for _, testCase := range testCases {
+		if result := compareVersions(testCase.A, testCase.B); result != testCase.Expected {
+			t.Fatalf("expected comparing %s to %s to return %d, got %d", testCase.A, testCase.B, testCase.Expected, result)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"sync"
+
+	"github.com/giantswarm/kocho/provider"
+	"github.com/giantswarm/kocho/swarm/types"
+
+	"github.com/juju/errgo"
+)
+
+// MultiRegionProvider represents a Provider spanning multiple AWS regions.
+// Swarms are listed and looked up in all regions concurrently, and created
+// in the region of the session.
+type MultiRegionProvider struct {
+	home    AwsProvider
+	regions []AwsProvider
+}
+
+func newMultiRegionProvider(regions []string) MultiRegionProvider {
+	p := MultiRegionProvider{
+		home: newAwsProvider(""),
+	}
+	for _, region := range regions {
+		if region == p.home.region {
+			p.regions = append(p.regions, p.home)
+			continue
+		}
+		p.regions = append(p.regions, newAwsProvider(region))
+	}
+	return p
+}
+
+// GetSwarms returns a list of all the Swarms running in any of the regions.
+func (p MultiRegionProvider) GetSwarms(includeUnmanaged bool) ([]provider.ProviderSwarm, error) {
+	results := make([][]provider.ProviderSwarm, len(p.regions))
+	errs := make([]error, len(p.regions))
+
+	var wg sync.WaitGroup
+	for index, regionProvider := range p.regions {
+		wg.Add(1)
+		go func(index int, regionProvider AwsProvider) {
+			defer wg.Done()
+			results[index], errs[index] = regionProvider.GetSwarms(includeUnmanaged)
+		}(index, regionProvider)
+	}
+	wg.Wait()
+
+	var swarms []provider.ProviderSwarm
+	for index, err := range errs {
+		if err != nil {
+			return nil, errgo.WithCausef(err, nil, "failed to list swarms in region %s", p.regions[index].region)
+		}
+		swarms = append(swarms, results[index]...)
+	}
+	return swarms, nil
+}
+
+// GetSwarm returns the Swarm with the given name from whichever region it lives in.
+// If it exists in multiple regions, the one in the region of the session is preferred.
+func (p MultiRegionProvider) GetSwarm(name string) (provider.ProviderSwarm, error) {
+	results := make([]provider.ProviderSwarm, len(p.regions))
+	errs := make([]error, len(p.regions))
+
+	var wg sync.WaitGroup
+	for index, regionProvider := range p.regions {
+		wg.Add(1)
+		go func(index int, regionProvider AwsProvider) {
+			defer wg.Done()
+			results[index], errs[index] = regionProvider.GetSwarm(name)
+		}(index, regionProvider)
+	}
+	wg.Wait()
+
+	var found []provider.ProviderSwarm
+	for index, err := range errs {
+		if err == provider.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errgo.WithCausef(err, nil, "failed to look up swarm in region %s", p.regions[index].region)
+		}
+		if p.regions[index].region == p.home.region {
+			return results[index], nil
+		}
+		found = append(found, results[index])
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, provider.ErrNotFound
+	case 1:
+		return found[0], nil
+	default:
+		var regions []string
+		for _, s := range found {
+			regions = append(regions, s.GetRegion())
+		}
+		return nil, errgo.Newf("swarm %s exists in multiple regions %v, use --aws-region to select one", name, regions)
+	}
+}
+
+// CreateSwarm creates a Swarm in the region of the session.
+func (p MultiRegionProvider) CreateSwarm(name string, spec swarmtypes.Spec, cloudconfigText string) (provider.ProviderSwarm, error) {
+	return p.home.CreateSwarm(name, spec, cloudconfigText)
+}
@@ -21,10 +21,11 @@ type AutoScalingGroup struct {
 	Instances []AutoScalingInstance
 }
 
-// NewAutoScaling returns a new AutoScaling.
-func NewAutoScaling() *AutoScaling {
+// NewAutoScaling returns a new AutoScaling for the given region.
+// An empty region uses the region of the DefaultSessionProvider.
+func NewAutoScaling(region string) *AutoScaling {
 	return &AutoScaling{
-		client: autoscaling.New(DefaultSessionProvider.GetSession(), AutoScalingConfigs...),
+		client: autoscaling.New(DefaultSessionProvider.GetSessionInRegion(region), AutoScalingConfigs...),
 	}
 }
 
@@ -53,12 +54,13 @@ func (a AutoScaling) DescribeAutoScalingGroup(name string) (*AutoScalingGroup, e
 	}
 
 	for _, i := range resp.AutoScalingGroups[0].Instances {
+		// Instances launched from a launch template have no launch configuration
 		result.Instances = append(result.Instances, AutoScalingInstance{
-			InstanceId:              *i.InstanceId,
-			AvailabilityZone:        *i.AvailabilityZone,
-			HealthStatus:            *i.HealthStatus,
-			LifecycleState:          *i.LifecycleState,
-			LaunchConfigurationName: *i.LaunchConfigurationName,
+			InstanceId:              aws.StringValue(i.InstanceId),
+			AvailabilityZone:        aws.StringValue(i.AvailabilityZone),
+			HealthStatus:            aws.StringValue(i.HealthStatus),
+			LifecycleState:          aws.StringValue(i.LifecycleState),
+			LaunchConfigurationName: aws.StringValue(i.LaunchConfigurationName),
 		})
 	}
 
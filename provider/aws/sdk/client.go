@@ -2,11 +2,15 @@
 package sdk
 
 import (
+	"os"
 	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/defaults"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/juju/errgo"
 	"github.com/spf13/pflag"
 )
 
@@ -15,6 +19,8 @@ var (
 	EC2Configs            = []*aws.Config{}
 	CloudFormationConfigs = []*aws.Config{}
 	ELBConfigs            = []*aws.Config{}
+	S3Configs             = []*aws.Config{}
+	DynamoDBConfigs       = []*aws.Config{}
 )
 
 // SessionProvider represents the current AWS session.
@@ -30,10 +36,21 @@ var (
 		ConfigAccessKey:             "",
 		ConfigSecretKey:             "",
 		ConfigSessionToken:          "",
+		ConfigRoleSessionName:       defaultRoleSessionName,
+		ConfigCredentialsCache:      defaultCredentialsCache,
 	}
 )
 
 // ConfigurableSessionProvider represents a SessionProvider that can be configured.
+//
+// Credentials are looked up in the following order:
+//   - the static access and secret key, if given
+//   - environment variables, unless disabled
+//   - the shared credentials and config files, using the configured profile
+//   - the EC2 instance role or ECS task role
+//
+// If a role to assume is configured, the credentials found above are used to
+// assume it.
 type ConfigurableSessionProvider struct {
 	_session     *session.Session
 	sessionMutex sync.Mutex
@@ -43,6 +60,13 @@ type ConfigurableSessionProvider struct {
 	ConfigAccessKey             string
 	ConfigSecretKey             string
 	ConfigSessionToken          string
+
+	ConfigProfile          string
+	ConfigAssumeRole       string
+	ConfigExternalID       string
+	ConfigMFASerial        string
+	ConfigRoleSessionName  string
+	ConfigCredentialsCache string
 }
 
 // RegisterFlagSet registers command line flags with the SessionProvider.
@@ -54,34 +78,116 @@ func (dsp *ConfigurableSessionProvider) RegisterFlagSet(flagSet *pflag.FlagSet)
 	flagSet.StringVar(&dsp.ConfigSessionToken, "aws-session-token", dsp.ConfigSessionToken, "AWS Session Token - empty most of the time")
 
 	flagSet.BoolVar(&dsp.ConfigDisableENVCredentials, "disable-aws-env-credentials", dsp.ConfigDisableENVCredentials, "do not read credentials from environment variables")
+
+	flagSet.StringVar(&dsp.ConfigProfile, "aws-profile", dsp.ConfigProfile, "profile of the AWS shared credentials and config files to use")
+	flagSet.StringVar(&dsp.ConfigAssumeRole, "aws-assume-role", dsp.ConfigAssumeRole, "ARN of an IAM role to assume")
+	flagSet.StringVar(&dsp.ConfigExternalID, "aws-external-id", dsp.ConfigExternalID, "external id to use when assuming the role")
+	flagSet.StringVar(&dsp.ConfigMFASerial, "aws-mfa-serial", dsp.ConfigMFASerial, "serial number or ARN of the MFA device required to assume the role - the token is prompted for")
+	flagSet.StringVar(&dsp.ConfigRoleSessionName, "aws-role-session-name", dsp.ConfigRoleSessionName, "session name to use when assuming the role")
+	flagSet.StringVar(&dsp.ConfigCredentialsCache, "aws-credentials-cache", dsp.ConfigCredentialsCache, "directory to cache assumed role credentials in - empty disables caching")
 }
 
 // GetSessions returns the current session from the SessionProvider.
+//
+// It panics if no session can be created. Use Init to check the configuration beforehand.
 func (dsp *ConfigurableSessionProvider) GetSession() *session.Session {
+	if err := dsp.Init(); err != nil {
+		panic(err.Error())
+	}
+	return dsp._session
+}
+
+// GetSessionInRegion returns a copy of the current session, bound to the given
+// region. An empty region returns the current session.
+func (dsp *ConfigurableSessionProvider) GetSessionInRegion(region string) *session.Session {
+	sess := dsp.GetSession()
+	if region == "" {
+		return sess
+	}
+	return sess.Copy(aws.NewConfig().WithRegion(region))
+}
+
+// Init creates the session of the SessionProvider, if not yet done.
+// Credentials are not retrieved before the first request.
+func (dsp *ConfigurableSessionProvider) Init() error {
 	dsp.sessionMutex.Lock()
 	defer dsp.sessionMutex.Unlock()
 
 	if dsp._session != nil {
-		return dsp._session
+		return nil
+	}
+
+	sess, err := dsp.newBaseSession()
+	if err != nil {
+		return errgo.WithCausef(err, nil, "no valid configuration for aws credentials found")
 	}
 
-	cfgs := []*aws.Config{
-		aws.NewConfig().WithRegion(dsp.ConfigRegion),
+	if dsp.ConfigAssumeRole != "" {
+		sess = sess.Copy(aws.NewConfig().WithCredentials(credentials.NewCredentials(&AssumeRoleProvider{
+			Client:          sts.New(sess),
+			Source:          dsp.sourceIdentity(),
+			RoleARN:         dsp.ConfigAssumeRole,
+			RoleSessionName: dsp.ConfigRoleSessionName,
+			ExternalID:      dsp.ConfigExternalID,
+			MFASerial:       dsp.ConfigMFASerial,
+			CacheDir:        dsp.ConfigCredentialsCache,
+			TokenProvider:   StdinTokenProvider,
+		})))
 	}
 
+	dsp._session = sess
+	return nil
+}
+
+// sourceIdentity identifies the base credentials of the session without
+// retrieving them: the configured access key ID, or else the profile.
+func (dsp *ConfigurableSessionProvider) sourceIdentity() string {
 	if dsp.ConfigAccessKey != "" {
-		cfgs = append(cfgs, aws.NewConfig().WithCredentials(credentials.NewStaticCredentials(
+		return "key:" + dsp.ConfigAccessKey
+	}
+	if !dsp.ConfigDisableENVCredentials {
+		if key := os.Getenv("AWS_ACCESS_KEY_ID"); key != "" {
+			return "key:" + key
+		}
+	}
+
+	profile := dsp.ConfigProfile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	return "profile:" + profile
+}
+
+func (dsp *ConfigurableSessionProvider) newBaseSession() (*session.Session, error) {
+	cfg := aws.NewConfig().WithRegion(dsp.ConfigRegion)
+
+	if dsp.ConfigAccessKey != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(
 			dsp.ConfigAccessKey,
 			dsp.ConfigSecretKey,
 			dsp.ConfigSessionToken,
-		)))
-	} else if !dsp.ConfigDisableENVCredentials {
-		// NOTE: We may want to init all credential providers in one config, as they might overwrite each other
-		cfgs = append(cfgs, aws.NewConfig().WithCredentials(credentials.NewCredentials(&credentials.EnvProvider{})))
-	} else {
-		panic("no valid configuration parameters for aws credentials found.")
+		))
+		return session.NewSession(cfg)
 	}
 
-	dsp._session = session.New(cfgs...)
-	return dsp._session
+	if dsp.ConfigDisableENVCredentials {
+		// The default chain of the SDK always consults the environment, so we
+		// build our own one without it.
+		defaultConfig := defaults.Config().WithRegion(dsp.ConfigRegion)
+		cfg = cfg.WithCredentials(credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.SharedCredentialsProvider{Profile: dsp.ConfigProfile},
+			defaults.RemoteCredProvider(*defaultConfig, defaults.Handlers()),
+		}))
+		return session.NewSession(cfg)
+	}
+
+	return session.NewSessionWithOptions(session.Options{
+		Config:                  *cfg,
+		Profile:                 dsp.ConfigProfile,
+		SharedConfigState:       session.SharedConfigEnable,
+		AssumeRoleTokenProvider: func() (string, error) { return StdinTokenProvider("the profile's MFA device") },
+	})
 }
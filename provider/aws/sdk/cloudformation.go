@@ -3,6 +3,7 @@ package sdk
 import (
 	"encoding/json"
 	"io/ioutil"
+	"strings"
 	"time"
 
 	"github.com/giantswarm/kocho/provider"
@@ -12,6 +13,36 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/juju/errgo"
+)
+
+const (
+	// StackTypeTag is the tag holding the swarm type of a stack.
+	StackTypeTag = "StackType"
+
+	// ManagedByTag marks stacks created by kocho, with ManagedByValue as value.
+	ManagedByTag   = "ManagedBy"
+	ManagedByValue = "kocho"
+
+	stackStatusDeleteComplete         = "DELETE_COMPLETE"
+	stackStatusUpdateRollbackComplete = "UPDATE_ROLLBACK_COMPLETE"
+
+	changeSetStatusFailed   = "FAILED"
+	changeSetStatusPending  = "CREATE_PENDING"
+	changeSetStatusCreating = "CREATE_IN_PROGRESS"
+	changeSetWaitInterval   = 2 * time.Second
+
+	driftDetectionInProgress = "DETECTION_IN_PROGRESS"
+	driftDetectionFailed     = "DETECTION_FAILED"
+	driftStatusInSync        = "IN_SYNC"
+	driftWaitInterval        = 5 * time.Second
+
+	// MaxTemplateBodySize is the largest template CloudFormation accepts inline.
+	// Larger templates have to be passed by URL of an S3 object.
+	MaxTemplateBodySize = 51200
+
+	// MaxStackTags is the largest number of tags CloudFormation accepts for a stack.
+	MaxStackTags = 50
 )
 
 // Stacks represents a list containing multiple Stack.
@@ -26,7 +57,22 @@ type Stack struct {
 	Status       string `json:"StackStatus"`
 	StatusReason string `json:"StackStatusReason"`
 	Tags         []types.Tag
+	Outputs      map[string]string
+	Parameters   map[string]string
+	Capabilities []string
 	CreationTime time.Time
+
+	// TerminationProtection prevents the stack from being deleted
+	TerminationProtection bool
+}
+
+// ChangeSet represents a CloudFormation change set.
+type ChangeSet struct {
+	Id           string
+	StackName    string
+	Status       string
+	StatusReason string
+	Changes      []types.ResourceChange
 }
 
 // StackResources represents a list containing multiple StackResource.
@@ -34,10 +80,11 @@ type StackResources struct {
 	StackResources []types.StackResource
 }
 
-// NewCloudFormation returns a new CloudFormation.
-func NewCloudFormation() *CloudFormation {
+// NewCloudFormation returns a new CloudFormation for the given region.
+// An empty region uses the region of the DefaultSessionProvider.
+func NewCloudFormation(region string) *CloudFormation {
 	return &CloudFormation{
-		client: cloudformation.New(DefaultSessionProvider.GetSession(), CloudFormationConfigs...),
+		client: cloudformation.New(DefaultSessionProvider.GetSessionInRegion(region), CloudFormationConfigs...),
 	}
 }
 
@@ -46,32 +93,50 @@ type CloudFormation struct {
 	client cloudformationiface.CloudFormationAPI
 }
 
-// CreateStack creates a CloudFormation stack, given a name, a type of stack, and template and parameters files.
-func (c CloudFormation) CreateStack(name, stackType, templateFile, parametersFile string) (*Stack, error) {
+// CreateStack creates a CloudFormation stack, given a name, a type of stack, template and parameters files,
+// and additional tags to add to the stack. If templateURL is not empty, the template is read from
+// there instead of the template file.
+func (c CloudFormation) CreateStack(name, stackType, templateFile, templateURL, parametersFile string, tags map[string]string) (*Stack, error) {
 	var awsParameters []*cloudformation.Parameter
 	if err := c.loadFile(parametersFile, &awsParameters); err != nil {
 		return nil, err
 	}
 
-	var templateBody string
-	if data, err := ioutil.ReadFile(templateFile); err != nil {
-		return nil, err
-	} else {
-		templateBody = string(data)
-	}
-
 	input := &cloudformation.CreateStackInput{
-		StackName:    aws.String(name),
-		TemplateBody: aws.String(templateBody),
-		Parameters:   awsParameters,
+		StackName:  aws.String(name),
+		Parameters: awsParameters,
 		Tags: []*cloudformation.Tag{
 			{
-				Key:   aws.String("StackType"),
+				Key:   aws.String(StackTypeTag),
 				Value: aws.String(stackType),
 			},
+			{
+				Key:   aws.String(ManagedByTag),
+				Value: aws.String(ManagedByValue),
+			},
 		},
 	}
 
+	for key, value := range tags {
+		input.Tags = append(input.Tags, &cloudformation.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(value),
+		})
+	}
+	if err := checkTagCount(name, input.Tags); err != nil {
+		return nil, err
+	}
+
+	if templateURL != "" {
+		input.TemplateURL = aws.String(templateURL)
+	} else {
+		templateBody, err := ioutil.ReadFile(templateFile)
+		if err != nil {
+			return nil, err
+		}
+		input.TemplateBody = aws.String(string(templateBody))
+	}
+
 	resp, err := c.client.CreateStack(input)
 	if err != nil {
 		return nil, err
@@ -95,10 +160,21 @@ func (c CloudFormation) DescribeStack(name string) (*Stack, error) {
 	return &stacks.Stacks[0], nil
 }
 
-// DescribeStacks returns all Stacks.
+// DescribeStacks returns all Stacks, except for deleted ones.
 func (c CloudFormation) DescribeStacks() (*Stacks, error) {
-	stacks, err := c.describeStacks(nil)
-	return stacks, err
+	stacks, err := c.describeStacks(&cloudformation.DescribeStacksInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Stacks{Stacks: []Stack{}}
+	for _, stack := range stacks.Stacks {
+		if stack.Status == stackStatusDeleteComplete {
+			continue
+		}
+		result.Stacks = append(result.Stacks, stack)
+	}
+	return result, nil
 }
 
 // DescribeStackResources returns the StackResources, given the name of a stack.
@@ -138,32 +214,335 @@ func (c CloudFormation) DeleteStack(name string) error {
 	return nil
 }
 
-func (c CloudFormation) describeStacks(input *cloudformation.DescribeStacksInput) (*Stacks, error) {
-	resp, err := c.client.DescribeStacks(input)
+// SetTerminationProtection enables or disables the termination protection of the given stack.
+func (c CloudFormation) SetTerminationProtection(name string, enabled bool) error {
+	_, err := c.client.UpdateTerminationProtection(&cloudformation.UpdateTerminationProtectionInput{
+		StackName:                   aws.String(name),
+		EnableTerminationProtection: aws.Bool(enabled),
+	})
+	if err != nil {
+		return maskAny(err)
+	}
+	return nil
+}
+
+// UpdateStackTags sets and removes the given tags of a stack, keeping its
+// template and parameters, and waits for the update to complete. Stack tags
+// are propagated to the resources of the stack, so the update can fail and
+// be rolled back for reasons unrelated to the tags.
+func (c CloudFormation) UpdateStackTags(name string, set map[string]string, remove []string) error {
+	stack, err := c.DescribeStack(name)
+	if err != nil {
+		return err
+	}
+
+	tags := map[string]string{}
+	for _, tag := range stack.Tags {
+		tags[tag.Key] = tag.Value
+	}
+
+	changed := false
+	for key, value := range set {
+		if current, ok := tags[key]; !ok || current != value {
+			tags[key] = value
+			changed = true
+		}
+	}
+	for _, key := range remove {
+		if _, ok := tags[key]; ok {
+			delete(tags, key)
+			changed = true
+		}
+	}
+	// CloudFormation refuses updates without changes
+	if !changed {
+		return nil
+	}
+
+	input := &cloudformation.UpdateStackInput{
+		StackName:           aws.String(name),
+		UsePreviousTemplate: aws.Bool(true),
+		Capabilities:        aws.StringSlice(stack.Capabilities),
+		Tags:                []*cloudformation.Tag{},
+	}
+	for key := range stack.Parameters {
+		input.Parameters = append(input.Parameters, &cloudformation.Parameter{
+			ParameterKey:     aws.String(key),
+			UsePreviousValue: aws.Bool(true),
+		})
+	}
+	for key, value := range tags {
+		input.Tags = append(input.Tags, &cloudformation.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(value),
+		})
+	}
+
+	if _, err := c.client.UpdateStack(input); err != nil {
+		return maskAny(err)
+	}
+
+	err = c.client.WaitUntilStackUpdateComplete(&cloudformation.DescribeStacksInput{
+		StackName: aws.String(name),
+	})
 	if err != nil {
-		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ValidationError" {
-			return nil, provider.ErrNotFound
+		if stack, describeErr := c.DescribeStack(name); describeErr == nil && stack.Status == stackStatusUpdateRollbackComplete {
+			return errgo.Newf("updating the tags of stack %s failed and was rolled back: %s", name, stack.StatusReason)
+		}
+		return maskAny(err)
+	}
+	return nil
+}
+
+// CreateChangeSet creates a change set updating the given stack to the template
+// and parameters files and tags, and waits until its changes are computed.
+// Change sets without changes are deleted, and returned without changes.
+// If templateURL is not empty, the template is read from there instead of the template file.
+func (c CloudFormation) CreateChangeSet(stackName, changeSetName, templateFile, templateURL, parametersFile string, capabilities []string, tags map[string]string) (*ChangeSet, error) {
+	var awsParameters []*cloudformation.Parameter
+	if err := c.loadFile(parametersFile, &awsParameters); err != nil {
+		return nil, err
+	}
+
+	input := &cloudformation.CreateChangeSetInput{
+		StackName:     aws.String(stackName),
+		ChangeSetName: aws.String(changeSetName),
+		ChangeSetType: aws.String(cloudformation.ChangeSetTypeUpdate),
+		Parameters:    awsParameters,
+		Capabilities:  aws.StringSlice(capabilities),
+		Tags:          []*cloudformation.Tag{},
+	}
+	for key, value := range tags {
+		input.Tags = append(input.Tags, &cloudformation.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(value),
+		})
+	}
+	if err := checkTagCount(stackName, input.Tags); err != nil {
+		return nil, err
+	}
+
+	if templateURL != "" {
+		input.TemplateURL = aws.String(templateURL)
+	} else {
+		templateBody, err := ioutil.ReadFile(templateFile)
+		if err != nil {
+			return nil, err
 		}
+		input.TemplateBody = aws.String(string(templateBody))
+	}
+
+	resp, err := c.client.CreateChangeSet(input)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	changeSet, err := c.waitForChangeSet(aws.StringValue(resp.Id))
+	if err != nil {
 		return nil, err
 	}
 
+	if changeSet.Status == changeSetStatusFailed {
+		if len(changeSet.Changes) == 0 && isNoChangesReason(changeSet.StatusReason) {
+			return changeSet, c.DeleteChangeSet(changeSet.Id)
+		}
+		return nil, errgo.Newf("failed to create change set for stack %s: %s", stackName, changeSet.StatusReason)
+	}
+	return changeSet, nil
+}
+
+// DescribeChangeSet returns the change set with the given ID, including all its changes.
+func (c CloudFormation) DescribeChangeSet(id string) (*ChangeSet, error) {
+	input := &cloudformation.DescribeChangeSetInput{
+		ChangeSetName: aws.String(id),
+	}
+
+	var changeSet *ChangeSet
+	for {
+		resp, err := c.client.DescribeChangeSet(input)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+
+		if changeSet == nil {
+			changeSet = &ChangeSet{
+				Id:           aws.StringValue(resp.ChangeSetId),
+				StackName:    aws.StringValue(resp.StackName),
+				Status:       aws.StringValue(resp.Status),
+				StatusReason: aws.StringValue(resp.StatusReason),
+			}
+		}
+
+		for _, change := range resp.Changes {
+			if change.ResourceChange == nil {
+				continue
+			}
+			changeSet.Changes = append(changeSet.Changes, types.ResourceChange{
+				Action:      aws.StringValue(change.ResourceChange.Action),
+				Type:        aws.StringValue(change.ResourceChange.ResourceType),
+				LogicalId:   aws.StringValue(change.ResourceChange.LogicalResourceId),
+				PhysicalId:  aws.StringValue(change.ResourceChange.PhysicalResourceId),
+				Replacement: aws.StringValue(change.ResourceChange.Replacement),
+			})
+		}
+
+		if resp.NextToken == nil || *resp.NextToken == "" {
+			return changeSet, nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
+
+// ExecuteChangeSet starts the update of a stack with the given change set.
+func (c CloudFormation) ExecuteChangeSet(id string) error {
+	_, err := c.client.ExecuteChangeSet(&cloudformation.ExecuteChangeSetInput{
+		ChangeSetName: aws.String(id),
+	})
+	if err != nil {
+		return maskAny(err)
+	}
+	return nil
+}
+
+// DeleteChangeSet deletes the change set with the given ID.
+func (c CloudFormation) DeleteChangeSet(id string) error {
+	_, err := c.client.DeleteChangeSet(&cloudformation.DeleteChangeSetInput{
+		ChangeSetName: aws.String(id),
+	})
+	if err != nil {
+		return maskAny(err)
+	}
+	return nil
+}
+
+// waitForChangeSet waits until the changes of the change set are computed.
+func (c CloudFormation) waitForChangeSet(id string) (*ChangeSet, error) {
+	for {
+		changeSet, err := c.DescribeChangeSet(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if changeSet.Status != changeSetStatusPending && changeSet.Status != changeSetStatusCreating {
+			return changeSet, nil
+		}
+		time.Sleep(changeSetWaitInterval)
+	}
+}
+
+// isNoChangesReason returns true if a change set failed because the stack is up to date.
+func isNoChangesReason(reason string) bool {
+	return strings.Contains(reason, "didn't contain changes") || strings.Contains(reason, "No updates are to be performed")
+}
+
+// GetTemplate returns the template body of the given stack.
+func (c CloudFormation) GetTemplate(name string) (string, error) {
+	resp, err := c.client.GetTemplate(&cloudformation.GetTemplateInput{
+		StackName: aws.String(name),
+	})
+	if err != nil {
+		return "", maskAny(err)
+	}
+	return aws.StringValue(resp.TemplateBody), nil
+}
+
+// DetectStackDrift runs drift detection on the given stack, waits for it to
+// finish, and returns all resources that are not in sync with the template.
+func (c CloudFormation) DetectStackDrift(name string) ([]types.ResourceDrift, error) {
+	detection, err := c.client.DetectStackDrift(&cloudformation.DetectStackDriftInput{
+		StackName: aws.String(name),
+	})
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	for {
+		status, err := c.client.DescribeStackDriftDetectionStatus(&cloudformation.DescribeStackDriftDetectionStatusInput{
+			StackDriftDetectionId: detection.StackDriftDetectionId,
+		})
+		if err != nil {
+			return nil, maskAny(err)
+		}
+
+		if aws.StringValue(status.DetectionStatus) == driftDetectionFailed {
+			return nil, errgo.Newf("drift detection of stack %s failed: %s", name, aws.StringValue(status.DetectionStatusReason))
+		}
+		if aws.StringValue(status.DetectionStatus) != driftDetectionInProgress {
+			break
+		}
+		time.Sleep(driftWaitInterval)
+	}
+
+	input := &cloudformation.DescribeStackResourceDriftsInput{
+		StackName: aws.String(name),
+	}
+
+	var drifts []types.ResourceDrift
+	for {
+		resp, err := c.client.DescribeStackResourceDrifts(input)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+
+		for _, drift := range resp.StackResourceDrifts {
+			if aws.StringValue(drift.StackResourceDriftStatus) == driftStatusInSync {
+				continue
+			}
+			drifts = append(drifts, types.ResourceDrift{
+				Type:       aws.StringValue(drift.ResourceType),
+				LogicalId:  aws.StringValue(drift.LogicalResourceId),
+				PhysicalId: aws.StringValue(drift.PhysicalResourceId),
+				Status:     aws.StringValue(drift.StackResourceDriftStatus),
+			})
+		}
+
+		if resp.NextToken == nil || *resp.NextToken == "" {
+			return drifts, nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
+
+// describeStacks returns the Stacks matching the given input, following all
+// pages of the result.
+func (c CloudFormation) describeStacks(input *cloudformation.DescribeStacksInput) (*Stacks, error) {
 	result := &Stacks{Stacks: []Stack{}}
-	for _, awsStack := range resp.Stacks {
-		stack := Stack{
-			Id:           *awsStack.StackId,
-			Name:         *awsStack.StackName,
-			Status:       *awsStack.StackStatus,
-			Tags:         fromCloudFormationTags(awsStack.Tags),
-			CreationTime: *awsStack.CreationTime,
+
+	for {
+		resp, err := c.client.DescribeStacks(input)
+		if err != nil {
+			if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ValidationError" {
+				return nil, provider.ErrNotFound
+			}
+			return nil, err
 		}
 
-		if awsStack.StackStatusReason != nil {
-			stack.StatusReason = *awsStack.StackStatusReason
+		for _, awsStack := range resp.Stacks {
+			stack := Stack{
+				Id:           *awsStack.StackId,
+				Name:         *awsStack.StackName,
+				Status:       *awsStack.StackStatus,
+				Tags:         fromCloudFormationTags(awsStack.Tags),
+				Outputs:      fromCloudFormationOutputs(awsStack.Outputs),
+				Parameters:   fromCloudFormationParameters(awsStack.Parameters),
+				Capabilities: aws.StringValueSlice(awsStack.Capabilities),
+				CreationTime: *awsStack.CreationTime,
+
+				TerminationProtection: aws.BoolValue(awsStack.EnableTerminationProtection),
+			}
+
+			if awsStack.StackStatusReason != nil {
+				stack.StatusReason = *awsStack.StackStatusReason
+			}
+
+			result.Stacks = append(result.Stacks, stack)
 		}
 
-		result.Stacks = append(result.Stacks, stack)
+		if resp.NextToken == nil || *resp.NextToken == "" {
+			return result, nil
+		}
+		input.NextToken = resp.NextToken
 	}
-	return result, nil
 }
 
 func (c CloudFormation) loadFile(file string, target interface{}) error {
@@ -174,6 +553,14 @@ func (c CloudFormation) loadFile(file string, target interface{}) error {
 	return json.Unmarshal(data, target)
 }
 
+// checkTagCount returns an error if the stack would have more tags than CloudFormation accepts.
+func checkTagCount(stackName string, tags []*cloudformation.Tag) error {
+	if len(tags) > MaxStackTags {
+		return errgo.Newf("stack %s would have %d tags, but CloudFormation accepts at most %d", stackName, len(tags), MaxStackTags)
+	}
+	return nil
+}
+
 func fromCloudFormationTags(tags []*cloudformation.Tag) []types.Tag {
 	result := make([]types.Tag, 0, len(tags))
 
@@ -186,3 +573,23 @@ func fromCloudFormationTags(tags []*cloudformation.Tag) []types.Tag {
 
 	return result
 }
+
+func fromCloudFormationOutputs(outputs []*cloudformation.Output) map[string]string {
+	result := make(map[string]string, len(outputs))
+
+	for _, output := range outputs {
+		result[aws.StringValue(output.OutputKey)] = aws.StringValue(output.OutputValue)
+	}
+
+	return result
+}
+
+func fromCloudFormationParameters(parameters []*cloudformation.Parameter) map[string]string {
+	result := make(map[string]string, len(parameters))
+
+	for _, parameter := range parameters {
+		result[aws.StringValue(parameter.ParameterKey)] = aws.StringValue(parameter.ParameterValue)
+	}
+
+	return result
+}
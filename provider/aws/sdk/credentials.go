@@ -0,0 +1,186 @@
+package sdk
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/juju/errgo"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+const (
+	defaultRoleSessionName    = "kocho"
+	defaultRoleSessionTimeout = time.Hour
+	defaultCredentialsCache   = "~/.giantswarm/kocho/aws-credentials"
+
+	// expiryWindow makes cached credentials expire a bit earlier, so they are
+	// not used right before AWS refuses them.
+	expiryWindow = time.Minute
+)
+
+// AssumeRoleProvider is a credentials.Provider that assumes the given role
+// using STS. If a CacheDir is given, the temporary credentials are cached
+// there, so users of MFA devices don't need to enter a token for every call.
+type AssumeRoleProvider struct {
+	credentials.Expiry
+
+	Client stsiface.STSAPI
+
+	// Source identifies the credentials the role is assumed with, e.g. their
+	// profile or access key ID, so switching them doesn't reuse cached credentials.
+	Source string
+
+	RoleARN         string
+	RoleSessionName string
+	ExternalID      string
+	MFASerial       string
+	Duration        time.Duration
+	CacheDir        string
+
+	// TokenProvider returns a MFA token code. Only called if MFASerial is set.
+	TokenProvider func(mfaSerial string) (string, error)
+}
+
+type cachedCredentials struct {
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token"`
+	Expiration      time.Time `json:"expiration"`
+}
+
+// Retrieve returns cached credentials if they are still valid, and otherwise
+// assumes the role.
+func (p *AssumeRoleProvider) Retrieve() (credentials.Value, error) {
+	if cached, ok := p.readCache(); ok {
+		p.SetExpiration(cached.Expiration, expiryWindow)
+		return p.toValue(cached), nil
+	}
+
+	duration := p.Duration
+	if duration == 0 {
+		duration = defaultRoleSessionTimeout
+	}
+	sessionName := p.RoleSessionName
+	if sessionName == "" {
+		sessionName = defaultRoleSessionName
+	}
+
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(p.RoleARN),
+		RoleSessionName: aws.String(sessionName),
+		DurationSeconds: aws.Int64(int64(duration / time.Second)),
+	}
+	if p.ExternalID != "" {
+		input.ExternalId = aws.String(p.ExternalID)
+	}
+	if p.MFASerial != "" {
+		if p.TokenProvider == nil {
+			return credentials.Value{}, errgo.Newf("assuming role %s requires a MFA token, but no token provider is configured", p.RoleARN)
+		}
+		token, err := p.TokenProvider(p.MFASerial)
+		if err != nil {
+			return credentials.Value{}, errgo.Mask(err)
+		}
+		input.SerialNumber = aws.String(p.MFASerial)
+		input.TokenCode = aws.String(token)
+	}
+
+	resp, err := p.Client.AssumeRole(input)
+	if err != nil {
+		return credentials.Value{}, errgo.WithCausef(err, nil, "failed to assume role %s", p.RoleARN)
+	}
+
+	cached := cachedCredentials{
+		AccessKeyID:     aws.StringValue(resp.Credentials.AccessKeyId),
+		SecretAccessKey: aws.StringValue(resp.Credentials.SecretAccessKey),
+		SessionToken:    aws.StringValue(resp.Credentials.SessionToken),
+		Expiration:      aws.TimeValue(resp.Credentials.Expiration),
+	}
+	p.SetExpiration(cached.Expiration, expiryWindow)
+	p.writeCache(cached)
+
+	return p.toValue(cached), nil
+}
+
+func (p *AssumeRoleProvider) toValue(c cachedCredentials) credentials.Value {
+	return credentials.Value{
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+		SessionToken:    c.SessionToken,
+		ProviderName:    "AssumeRoleProvider",
+	}
+}
+
+// cacheFile returns the file to cache the credentials in. The name is derived
+// from everything that influences which credentials are returned.
+func (p *AssumeRoleProvider) cacheFile() (string, error) {
+	if p.CacheDir == "" {
+		return "", nil
+	}
+	dir, err := homedir.Expand(p.CacheDir)
+	if err != nil {
+		return "", err
+	}
+
+	key := strings.Join([]string{p.Source, p.RoleARN, p.RoleSessionName, p.ExternalID, p.MFASerial}, "|")
+	return filepath.Join(dir, fmt.Sprintf("%x.json", sha1.Sum([]byte(key)))), nil
+}
+
+func (p *AssumeRoleProvider) readCache() (cachedCredentials, bool) {
+	var cached cachedCredentials
+
+	path, err := p.cacheFile()
+	if err != nil || path == "" {
+		return cached, false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cached, false
+	}
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return cached, false
+	}
+	if time.Now().Add(expiryWindow).After(cached.Expiration) {
+		return cached, false
+	}
+	return cached, true
+}
+
+// writeCache stores the credentials. Failing to cache is not fatal, the role
+// is just assumed again on the next invocation.
+func (p *AssumeRoleProvider) writeCache(cached cachedCredentials) {
+	path, err := p.cacheFile()
+	if err != nil || path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	ioutil.WriteFile(path, data, 0600)
+}
+
+// StdinTokenProvider prompts for a MFA token code on stdin.
+func StdinTokenProvider(mfaSerial string) (string, error) {
+	fmt.Fprintf(os.Stderr, "MFA token for %s: ", mfaSerial)
+
+	line, _, err := bufio.NewReader(os.Stdin).ReadLine()
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+	return strings.TrimSpace(string(line)), nil
+}
@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+type fakeSTS struct {
+	stsiface.STSAPI
+
+	calls     int
+	lastInput *sts.AssumeRoleInput
+}
+
+func (f *fakeSTS) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	f.calls++
+	f.lastInput = input
+	return &sts.AssumeRoleOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("access-key"),
+			SecretAccessKey: aws.String("secret-key"),
+			SessionToken:    aws.String("session-token"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+	}, nil
+}
+
+// TestAssumeRoleProviderCache checks that assumed role credentials are cached,
+// so the MFA token is only requested once.
+func TestAssumeRoleProviderCache(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "kocho-credentials")
+	if err != nil {
+		t.Fatalf("couldn't create cache dir: %v", err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	client := &fakeSTS{}
+	tokenRequests := 0
+	newProvider := func() *AssumeRoleProvider {
+		return &AssumeRoleProvider{
+			Client:     client,
+			RoleARN:    "arn:aws:iam::123456789012:role/kocho",
+			ExternalID: "external",
+			MFASerial:  "arn:aws:iam::123456789012:mfa/user",
+			CacheDir:   cacheDir,
+			TokenProvider: func(string) (string, error) {
+				tokenRequests++
+				return "123456", nil
+			},
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := newProvider().Retrieve()
+		if err != nil {
+			t.Fatalf("expected credentials, got error: %v", err)
+		}
+		if value.AccessKeyID != "access-key" || value.SessionToken != "session-token" {
+			t.Fatalf("unexpected credentials: %#v", value)
+		}
+	}
+
+	if client.calls != 1 || tokenRequests != 1 {
+		t.Fatalf("expected role to be assumed once, got %d calls and %d token requests", client.calls, tokenRequests)
+	}
+	if aws.StringValue(client.lastInput.ExternalId) != "external" || aws.StringValue(client.lastInput.TokenCode) != "123456" {
+		t.Fatalf("expected external id and token code to be passed, got %#v", client.lastInput)
+	}
+}
+
+// TestAssumeRoleProviderCacheSource checks that credentials cached for one
+// source identity aren't returned for another one.
+func TestAssumeRoleProviderCacheSource(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "kocho-credentials")
+	if err != nil {
+		t.Fatalf("couldn't create cache dir: %v", err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	client := &fakeSTS{}
+	for _, source := range []string{"profile:alice", "profile:bob"} {
+		provider := &AssumeRoleProvider{
+			Client:   client,
+			Source:   source,
+			RoleARN:  "arn:aws:iam::123456789012:role/kocho",
+			CacheDir: cacheDir,
+		}
+		if _, err := provider.Retrieve(); err != nil {
+			t.Fatalf("expected credentials, got error: %v", err)
+		}
+	}
+
+	if client.calls != 2 {
+		t.Fatalf("expected role to be assumed once per source, got %d calls", client.calls)
+	}
+}
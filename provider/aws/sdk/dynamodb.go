@@ -0,0 +1,116 @@
+package sdk
+
+import (
+	"github.com/giantswarm/kocho/provider"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// NewDynamoDB returns a new DynamoDB for the given region.
+// An empty region uses the region of the DefaultSessionProvider.
+func NewDynamoDB(region string) *DynamoDB {
+	return &DynamoDB{
+		client: dynamodb.New(DefaultSessionProvider.GetSessionInRegion(region), DynamoDBConfigs...),
+	}
+}
+
+// DynamoDB represents the DynamoDB API, for items of string attributes.
+type DynamoDB struct {
+	client dynamodbiface.DynamoDBAPI
+}
+
+// GetItem returns the item with the given key, or provider.ErrNotFound if
+// there is no such item. Attributes other than strings are left out.
+func (d DynamoDB) GetItem(table string, key map[string]string) (map[string]string, error) {
+	resp, err := d.client.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            stringAttributes(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	if len(resp.Item) == 0 {
+		return nil, provider.ErrNotFound
+	}
+
+	item := map[string]string{}
+	for name, value := range resp.Item {
+		if value.S != nil {
+			item[name] = *value.S
+		}
+	}
+	return item, nil
+}
+
+// PutItem stores the item if the condition holds, and returns
+// provider.ErrConditionFailed otherwise. An empty condition always holds.
+func (d DynamoDB) PutItem(table string, item map[string]string, condition string, names, values map[string]string) error {
+	_, err := d.client.PutItem(&dynamodb.PutItemInput{
+		TableName:                 aws.String(table),
+		Item:                      stringAttributes(item),
+		ConditionExpression:       conditionExpression(condition),
+		ExpressionAttributeNames:  attributeNames(names),
+		ExpressionAttributeValues: stringAttributes(values),
+	})
+	return conditionalWriteError(err)
+}
+
+// DeleteItem removes the item with the given key if the condition holds, and
+// returns provider.ErrConditionFailed otherwise. An empty condition always holds.
+func (d DynamoDB) DeleteItem(table string, key map[string]string, condition string, names, values map[string]string) error {
+	_, err := d.client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:                 aws.String(table),
+		Key:                       stringAttributes(key),
+		ConditionExpression:       conditionExpression(condition),
+		ExpressionAttributeNames:  attributeNames(names),
+		ExpressionAttributeValues: stringAttributes(values),
+	})
+	return conditionalWriteError(err)
+}
+
+func conditionalWriteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ConditionalCheckFailedException" {
+		return provider.ErrConditionFailed
+	}
+	return maskAny(err)
+}
+
+func conditionExpression(condition string) *string {
+	if condition == "" {
+		return nil
+	}
+	return aws.String(condition)
+}
+
+// stringAttributes returns the values as string attributes. DynamoDB rejects
+// empty attribute maps, so none are returned for no values.
+func stringAttributes(values map[string]string) map[string]*dynamodb.AttributeValue {
+	if len(values) == 0 {
+		return nil
+	}
+
+	attributes := map[string]*dynamodb.AttributeValue{}
+	for name, value := range values {
+		attributes[name] = &dynamodb.AttributeValue{S: aws.String(value)}
+	}
+	return attributes
+}
+
+func attributeNames(names map[string]string) map[string]*string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	result := map[string]*string{}
+	for placeholder, name := range names {
+		result[placeholder] = aws.String(name)
+	}
+	return result
+}
@@ -6,16 +6,21 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/juju/errgo"
 )
 
 const (
 	ec2StateRunning = "running"
+
+	// EC2LifecycleSpot is the lifecycle of spot instances.
+	EC2LifecycleSpot = "spot"
 )
 
-// NewEC2 returns a new EC2.
-func NewEC2() *EC2 {
+// NewEC2 returns a new EC2 for the given region.
+// An empty region uses the region of the DefaultSessionProvider.
+func NewEC2(region string) *EC2 {
 	return &EC2{
-		client: ec2.New(DefaultSessionProvider.GetSession(), EC2Configs...),
+		client: ec2.New(DefaultSessionProvider.GetSessionInRegion(region), EC2Configs...),
 	}
 }
 
@@ -33,11 +38,20 @@ func (e EC2) DescribeInstances(instanceIds []string) ([]types.Instance, error) {
 	for _, id := range instanceIds {
 		params.InstanceIds = append(params.InstanceIds, aws.String(id))
 	}
-	return e.describeInstances(params)
+	return e.describeInstances(params, true)
 }
 
-// FindInstancesByTags returns a list of Instances, given a list of Tags.
+// FindInstancesByTags returns a list of running Instances, given a list of Tags.
 func (e EC2) FindInstancesByTags(tags ...types.Tag) ([]types.Instance, error) {
+	return e.describeInstances(tagFilterInput(tags), true)
+}
+
+// FindAllInstancesByTags returns a list of Instances in any state, given a list of Tags.
+func (e EC2) FindAllInstancesByTags(tags ...types.Tag) ([]types.Instance, error) {
+	return e.describeInstances(tagFilterInput(tags), false)
+}
+
+func tagFilterInput(tags []types.Tag) *ec2.DescribeInstancesInput {
 	params := &ec2.DescribeInstancesInput{
 		Filters: []*ec2.Filter{},
 	}
@@ -47,10 +61,10 @@ func (e EC2) FindInstancesByTags(tags ...types.Tag) ([]types.Instance, error) {
 			Values: []*string{aws.String(tag.Value)},
 		})
 	}
-	return e.describeInstances(params)
+	return params
 }
 
-func (e EC2) describeInstances(input *ec2.DescribeInstancesInput) ([]types.Instance, error) {
+func (e EC2) describeInstances(input *ec2.DescribeInstancesInput, runningOnly bool) ([]types.Instance, error) {
 	resp, err := e.client.DescribeInstances(input)
 	if err != nil {
 		return nil, maskAny(err)
@@ -59,23 +73,171 @@ func (e EC2) describeInstances(input *ec2.DescribeInstancesInput) ([]types.Insta
 	result := make([]types.Instance, 0)
 	for _, reservation := range resp.Reservations {
 		for _, i := range reservation.Instances {
-			// For now we filter everything that is now running, as certain
-			// consumers of this expect all returned instances to be good instances
-			if *(i.State.Name) != ec2StateRunning {
+			inst := fromEC2Instance(i)
+
+			// Certain consumers of this expect all returned instances to be good
+			// instances, so they only get to see the running ones
+			if runningOnly && inst.State != ec2StateRunning {
 				continue
 			}
-			inst := types.Instance{
-				InstanceId:       *i.InstanceId,
-				ImageId:          *i.ImageId,
-				InstanceType:     *i.InstanceType,
-				PublicIPAddress:  *i.PublicIpAddress,
-				PublicDNSName:    *i.PublicDnsName,
-				PrivateIPAddress: *i.PrivateIpAddress,
-				PrivateDNSName:   *i.PrivateDnsName,
-			}
 
 			result = append(result, inst)
 		}
 	}
 	return result, nil
 }
+
+// fromEC2Instance converts an ec2.Instance into a types.Instance. Instances
+// which are not running may lack addresses, so all fields are read nil-safe.
+func fromEC2Instance(i *ec2.Instance) types.Instance {
+	inst := types.Instance{
+		InstanceId:       aws.StringValue(i.InstanceId),
+		ImageId:          aws.StringValue(i.ImageId),
+		InstanceType:     aws.StringValue(i.InstanceType),
+		LaunchTime:       aws.TimeValue(i.LaunchTime),
+		PublicIPAddress:  aws.StringValue(i.PublicIpAddress),
+		PublicDNSName:    aws.StringValue(i.PublicDnsName),
+		PrivateIPAddress: aws.StringValue(i.PrivateIpAddress),
+		PrivateDNSName:   aws.StringValue(i.PrivateDnsName),
+
+		Lifecycle:             aws.StringValue(i.InstanceLifecycle),
+		SpotInstanceRequestId: aws.StringValue(i.SpotInstanceRequestId),
+	}
+	if i.State != nil {
+		inst.State = aws.StringValue(i.State.Name)
+	}
+	if i.StateReason != nil {
+		inst.StateReason = aws.StringValue(i.StateReason.Code)
+	}
+	if i.Placement != nil {
+		inst.AvailabilityZone = aws.StringValue(i.Placement.AvailabilityZone)
+	}
+	return inst
+}
+
+// Subnet represents a subnet of a VPC.
+type Subnet struct {
+	SubnetId         string
+	VpcId            string
+	AvailabilityZone string
+}
+
+// DescribeSubnets returns the Subnets in the same order as the given subnet IDs.
+func (e EC2) DescribeSubnets(subnetIds []string) ([]Subnet, error) {
+	params := &ec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice(subnetIds),
+	}
+	resp, err := e.client.DescribeSubnets(params)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	subnets := map[string]Subnet{}
+	for _, s := range resp.Subnets {
+		subnets[aws.StringValue(s.SubnetId)] = Subnet{
+			SubnetId:         aws.StringValue(s.SubnetId),
+			VpcId:            aws.StringValue(s.VpcId),
+			AvailabilityZone: aws.StringValue(s.AvailabilityZone),
+		}
+	}
+
+	result := make([]Subnet, 0, len(subnetIds))
+	for _, id := range subnetIds {
+		subnet, ok := subnets[id]
+		if !ok {
+			return nil, errgo.Newf("subnet %s not found", id)
+		}
+		result = append(result, subnet)
+	}
+	return result, nil
+}
+
+// DescribeSpotInstanceRequestStatus returns the status codes of the given spot
+// instance requests, e.g. "fulfilled" or "marked-for-termination", indexed by
+// their request ID.
+func (e EC2) DescribeSpotInstanceRequestStatus(requestIds []string) (map[string]string, error) {
+	result := map[string]string{}
+	if len(requestIds) == 0 {
+		return result, nil
+	}
+
+	resp, err := e.client.DescribeSpotInstanceRequests(&ec2.DescribeSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: aws.StringSlice(requestIds),
+	})
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	for _, request := range resp.SpotInstanceRequests {
+		if request.Status == nil {
+			continue
+		}
+		result[aws.StringValue(request.SpotInstanceRequestId)] = aws.StringValue(request.Status.Code)
+	}
+	return result, nil
+}
+
+// DescribeRegions returns the names of all regions available to the account.
+func (e EC2) DescribeRegions() ([]string, error) {
+	resp, err := e.client.DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	regions := make([]string, 0, len(resp.Regions))
+	for _, region := range resp.Regions {
+		regions = append(regions, aws.StringValue(region.RegionName))
+	}
+	return regions, nil
+}
+
+// Image represents an AMI.
+type Image struct {
+	ImageId string
+	Name    string
+	OwnerId string
+}
+
+// FindImages returns the available HVM images of the given owner whose name
+// matches the given pattern. The pattern may contain '*' wildcards.
+func (e EC2) FindImages(ownerId, namePattern string) ([]Image, error) {
+	return e.describeImages(&ec2.DescribeImagesInput{
+		Owners: []*string{aws.String(ownerId)},
+		Filters: []*ec2.Filter{
+			{Name: aws.String("name"), Values: []*string{aws.String(namePattern)}},
+			{Name: aws.String("virtualization-type"), Values: []*string{aws.String("hvm")}},
+			{Name: aws.String("state"), Values: []*string{aws.String("available")}},
+		},
+	})
+}
+
+// DescribeImage returns the Image with the given ID.
+func (e EC2) DescribeImage(imageId string) (*Image, error) {
+	images, err := e.describeImages(&ec2.DescribeImagesInput{
+		ImageIds: []*string{aws.String(imageId)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, errgo.Newf("image %s not found", imageId)
+	}
+	return &images[0], nil
+}
+
+func (e EC2) describeImages(input *ec2.DescribeImagesInput) ([]Image, error) {
+	resp, err := e.client.DescribeImages(input)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	images := make([]Image, 0, len(resp.Images))
+	for _, i := range resp.Images {
+		images = append(images, Image{
+			ImageId: aws.StringValue(i.ImageId),
+			Name:    aws.StringValue(i.Name),
+			OwnerId: aws.StringValue(i.OwnerId),
+		})
+	}
+	return images, nil
+}
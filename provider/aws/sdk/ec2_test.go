@@ -0,0 +1,90 @@
+package sdk
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/giantswarm/kocho/provider/aws/types"
+)
+
+// TestFromEC2Instance checks if fromEC2Instance properly translates running
+// instances as well as instances without network addresses.
+func TestFromEC2Instance(t *testing.T) {
+	launchTime := time.Date(2016, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		EC2Instance   *ec2.Instance
+		KochoInstance types.Instance
+	}{
+		{
+			&ec2.Instance{
+				InstanceId:       pointer("i-1234"),
+				ImageId:          pointer("ami-5f2f5528"),
+				InstanceType:     pointer("m3.large"),
+				LaunchTime:       &launchTime,
+				State:            &ec2.InstanceState{Name: pointer("running")},
+				Placement:        &ec2.Placement{AvailabilityZone: pointer("eu-west-1a")},
+				PublicIpAddress:  pointer("52.1.2.3"),
+				PublicDnsName:    pointer("ec2-52-1-2-3.eu-west-1.compute.amazonaws.com"),
+				PrivateIpAddress: pointer("172.31.1.2"),
+				PrivateDnsName:   pointer("ip-172-31-1-2.eu-west-1.compute.internal"),
+			},
+			types.Instance{
+				InstanceId:       "i-1234",
+				ImageId:          "ami-5f2f5528",
+				InstanceType:     "m3.large",
+				State:            "running",
+				AvailabilityZone: "eu-west-1a",
+				LaunchTime:       launchTime,
+				PublicIPAddress:  "52.1.2.3",
+				PublicDNSName:    "ec2-52-1-2-3.eu-west-1.compute.amazonaws.com",
+				PrivateIPAddress: "172.31.1.2",
+				PrivateDNSName:   "ip-172-31-1-2.eu-west-1.compute.internal",
+			},
+		},
+		{
+			&ec2.Instance{
+				InstanceId:   pointer("i-5678"),
+				ImageId:      pointer("ami-5f2f5528"),
+				InstanceType: pointer("m3.large"),
+				State:        &ec2.InstanceState{Name: pointer("pending")},
+			},
+			types.Instance{
+				InstanceId:   "i-5678",
+				ImageId:      "ami-5f2f5528",
+				InstanceType: "m3.large",
+				State:        "pending",
+			},
+		},
+		{
+			&ec2.Instance{
+				InstanceId:            pointer("i-9012"),
+				ImageId:               pointer("ami-5f2f5528"),
+				InstanceType:          pointer("m4.large"),
+				InstanceLifecycle:     pointer("spot"),
+				SpotInstanceRequestId: pointer("sir-abcd"),
+				State:                 &ec2.InstanceState{Name: pointer("terminated")},
+				StateReason:           &ec2.StateReason{Code: pointer("Server.SpotInstanceTermination")},
+			},
+			types.Instance{
+				InstanceId:            "i-9012",
+				ImageId:               "ami-5f2f5528",
+				InstanceType:          "m4.large",
+				State:                 "terminated",
+				StateReason:           "Server.SpotInstanceTermination",
+				Lifecycle:             "spot",
+				SpotInstanceRequestId: "sir-abcd",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		instance := fromEC2Instance(testCase.EC2Instance)
+		if !reflect.DeepEqual(testCase.KochoInstance, instance) {
+			t.Fatalf("expected instance '%#v' to equal '%#v'", instance, testCase.KochoInstance)
+		}
+	}
+}
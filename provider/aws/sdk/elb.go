@@ -14,10 +14,11 @@ type LoadBalancer struct {
 	Scheme           string
 }
 
-// NewELB returns a new ELB.
-func NewELB() *ELB {
+// NewELB returns a new ELB for the given region.
+// An empty region uses the region of the DefaultSessionProvider.
+func NewELB(region string) *ELB {
 	return &ELB{
-		client: elb.New(DefaultSessionProvider.GetSession(), ELBConfigs...),
+		client: elb.New(DefaultSessionProvider.GetSessionInRegion(region), ELBConfigs...),
 	}
 }
 
@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/giantswarm/kocho/provider"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// NewS3 returns a new S3 for the given region.
+// An empty region uses the region of the DefaultSessionProvider.
+func NewS3(region string) *S3 {
+	return &S3{
+		client: s3.New(DefaultSessionProvider.GetSessionInRegion(region), S3Configs...),
+	}
+}
+
+// S3 represents the S3 API.
+type S3 struct {
+	client s3iface.S3API
+}
+
+// PutObject uploads the given data to the bucket and returns the URL of the object.
+func (s S3) PutObject(bucket, key string, data []byte) (string, error) {
+	req, _ := s.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err := req.Send(); err != nil {
+		return "", maskAny(err)
+	}
+
+	// The request URL is the URL of the object, including the regional endpoint of the bucket
+	url := *req.HTTPRequest.URL
+	url.RawQuery = ""
+	return url.String(), nil
+}
+
+// GetObject returns the data of the object with the given key, or
+// provider.ErrNotFound if there is no such object.
+func (s S3) GetObject(bucket, key string) ([]byte, error) {
+	resp, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "NoSuchKey" {
+			return nil, provider.ErrNotFound
+		}
+		return nil, maskAny(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	return data, nil
+}
+
+// DeleteObject deletes the object with the given key from the bucket.
+func (s S3) DeleteObject(bucket, key string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return maskAny(err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/provider/aws/types"
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+const (
+	// specTag is the stack tag holding the creation spec of a swarm as JSON.
+	// Stack tags are copied to all instances, so the spec is kept in as few
+	// tags as possible.
+	specTag = "kocho:spec"
+
+	// specTagPrefix prefixes all stack tags holding the creation spec of a swarm.
+	specTagPrefix = "kocho:"
+
+	// maxTagValueLength is the maximum number of characters of a stack tag
+	// value. Longer values are continued in tags with the suffix .1, .2 and so on.
+	maxTagValueLength = 256
+)
+
+// specTags returns the stack tags recording the given spec. The etcd discovery
+// url is a secret and the template bucket is not part of the spec, so they are
+// left out. Empty fields are left out to keep the value short.
+func specTags(spec swarmtypes.Spec) (map[string]string, error) {
+	spec.EtcdDiscoveryURL = ""
+	if spec.AWSCreateFlags != nil {
+		awsFlags := *spec.AWSCreateFlags
+		awsFlags.TemplateBucket = ""
+		awsFlags.TemplatePrefix = ""
+		spec.AWSCreateFlags = &awsFlags
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	for name, value := range fields {
+		switch value {
+		case nil, "", false, float64(0):
+			delete(fields, name)
+		}
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	tags := map[string]string{}
+	for index, chunk := range splitRunes(string(data), maxTagValueLength) {
+		if index == 0 {
+			tags[specTag] = chunk
+		} else {
+			tags[fmt.Sprintf("%s.%d", specTag, index)] = chunk
+		}
+	}
+	return tags, nil
+}
+
+// splitRunes splits value into chunks of at most n runes.
+func splitRunes(value string, n int) []string {
+	var chunks []string
+	runes := []rune(value)
+	for len(runes) > n {
+		chunks = append(chunks, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return append(chunks, string(runes))
+}
+
+// stackTags returns all tags to add to the stack of a new swarm.
+func stackTags(spec swarmtypes.Spec, i image) (map[string]string, error) {
+	tags, err := specTags(spec)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	for key, value := range imageTags(i) {
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// parseSpecTags returns the spec recorded in the given stack tags, or nil if
+// the stack was created without recording its spec.
+func parseSpecTags(tags []types.Tag) (*swarmtypes.Spec, error) {
+	values := map[string]string{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag.Key, specTag) {
+			values[tag.Key] = tag.Value
+		}
+	}
+
+	value, ok := values[specTag]
+	if !ok {
+		return nil, nil
+	}
+	for index := 1; ; index++ {
+		chunk, ok := values[fmt.Sprintf("%s.%d", specTag, index)]
+		if !ok {
+			break
+		}
+		value += chunk
+	}
+
+	spec := &swarmtypes.Spec{}
+	if err := json.Unmarshal([]byte(value), spec); err != nil {
+		return nil, errgo.Notef(err, "invalid spec in tag %s", specTag)
+	}
+	if spec.AWSCreateFlags == nil {
+		spec.AWSCreateFlags = &swarmtypes.AWSCreateFlags{}
+	}
+	return spec, nil
+}
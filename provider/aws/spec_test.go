@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/giantswarm/kocho/provider/aws/types"
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+func TestSpecTagsRoundTrip(t *testing.T) {
+	spec := swarmtypes.Spec{
+		CreateFlags: swarmtypes.CreateFlags{
+			Type:        "secondary",
+			ClusterSize: 5,
+			EtcdPeers:   strings.Repeat("http://10.0.0.1:2380,", 20),
+			Tags:        strings.Repeat("team=ünïcödé,", 5),
+			MachineType: "m3.large",
+			ImageURI:    "coreos:stable",
+			UseIgnition: true,
+			TTL:         48 * time.Hour,
+			AWSCreateFlags: &swarmtypes.AWSCreateFlags{
+				VPC:    "vpc-123",
+				Subnet: "subnet-1,subnet-2",
+			},
+		},
+		TemplateHash: "abc",
+		KochoVersion: "0.1.0",
+		Creator:      "jane",
+	}
+
+	specTags, err := specTags(spec)
+	if err != nil {
+		t.Fatalf("couldn't create spec tags: %v", err)
+	}
+	if len(specTags) > 4 {
+		t.Fatalf("expected spec to be stored in few tags, got %d", len(specTags))
+	}
+
+	var tags []types.Tag
+	for key, value := range specTags {
+		if length := utf8.RuneCountInString(value); length > maxTagValueLength {
+			t.Fatalf("expected tag %s to be at most %d characters, got %d", key, maxTagValueLength, length)
+		}
+		tags = append(tags, types.Tag{Key: key, Value: value})
+	}
+
+	parsed, err := parseSpecTags(tags)
+	if err != nil || parsed == nil {
+		t.Fatalf("expected spec to be parsed from tags, got %v", err)
+	}
+	if !reflect.DeepEqual(*parsed, spec) {
+		t.Fatalf("expected %#v, got %#v", spec, *parsed)
+	}
+}
+
+func TestSpecTagsOmitDiscoveryURL(t *testing.T) {
+	spec := swarmtypes.Spec{
+		CreateFlags: swarmtypes.CreateFlags{
+			Type:             "secondary",
+			EtcdDiscoveryURL: "https://discovery.etcd.io/secret",
+		},
+	}
+
+	tags, err := specTags(spec)
+	if err != nil {
+		t.Fatalf("couldn't create spec tags: %v", err)
+	}
+	for key, value := range tags {
+		if strings.Contains(value, "secret") {
+			t.Fatalf("expected discovery url to be left out, got %s=%s", key, value)
+		}
+	}
+}
+
+func TestSplitRunes(t *testing.T) {
+	chunks := splitRunes("aäöb", 3)
+	if !reflect.DeepEqual(chunks, []string{"aäö", "b"}) {
+		t.Fatalf("expected chunks split between runes, got %q", chunks)
+	}
+}
+
+func TestParseSpecTagsWithoutSpec(t *testing.T) {
+	if spec, err := parseSpecTags([]types.Tag{{Key: "StackType", Value: "primary"}}); spec != nil || err != nil {
+		t.Fatalf("expected no spec for stacks without spec tags, got %#v %v", spec, err)
+	}
+}
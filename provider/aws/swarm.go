@@ -2,28 +2,44 @@ package aws
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/giantswarm/kocho/provider"
+	"github.com/giantswarm/kocho/provider/aws/sdk"
 	"github.com/giantswarm/kocho/provider/aws/types"
 	"github.com/giantswarm/kocho/swarm/types"
 	"github.com/juju/errgo"
 )
 
 const (
-	statusCreateComplete   = "CREATE_COMPLETE"
-	statusRollbackComplete = "ROLLBACK_COMPLETE"
-	waitInterval           = 5 * time.Second
+	statusCreateComplete         = "CREATE_COMPLETE"
+	statusRollbackComplete       = "ROLLBACK_COMPLETE"
+	statusUpdateComplete         = "UPDATE_COMPLETE"
+	statusUpdateRollbackComplete = "UPDATE_ROLLBACK_COMPLETE"
+	statusUpdateRollbackFailed   = "UPDATE_ROLLBACK_FAILED"
+	waitInterval                 = 5 * time.Second
+
+	instanceStateTerminated = "terminated"
 )
 
 // AwsSwarm represents a Swarm running on AWS.
 type AwsSwarm struct {
+	Id           string
 	Name         string
 	Type         string
 	CreationTime time.Time
+	Tags         []types.Tag
+	Protected    bool
 	Provider     AwsProvider
 }
 
+// GetId returns the ID of the stack of the swarm.
+func (s AwsSwarm) GetId() string {
+	return s.Id
+}
+
 // GetName returns the name of the swarm.
 func (s AwsSwarm) GetName() string {
 	return s.Name
@@ -34,6 +50,11 @@ func (s AwsSwarm) GetType() string {
 	return s.Type
 }
 
+// GetRegion returns the AWS region the swarm lives in.
+func (s AwsSwarm) GetRegion() string {
+	return s.Provider.region
+}
+
 // GetCreationTime returns the time of creation of the swarm.
 func (s AwsSwarm) GetCreationTime() time.Time {
 	return s.CreationTime
@@ -79,7 +100,7 @@ func (s AwsSwarm) GetPrivateDNS() (string, error) {
 	return lb.DNSName, nil
 }
 
-// GetInstances returns all the instances of the swarm.
+// GetInstances returns all the running instances of the swarm.
 func (s AwsSwarm) GetInstances() ([]swarmtypes.Instance, error) {
 	awsInstances, err := s.Provider.ec2.FindInstancesByTags(types.Tag{
 		Key:   cloudFormationStackTag,
@@ -89,17 +110,119 @@ func (s AwsSwarm) GetInstances() ([]swarmtypes.Instance, error) {
 		return nil, err
 	}
 
+	return s.toSwarmInstances(awsInstances)
+}
+
+// GetAllInstances returns all the instances of the swarm, including those that
+// are pending, shutting down or already terminated.
+func (s AwsSwarm) GetAllInstances() ([]swarmtypes.Instance, error) {
+	awsInstances, err := s.Provider.ec2.FindAllInstancesByTags(types.Tag{
+		Key:   cloudFormationStackTag,
+		Value: s.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toSwarmInstances(awsInstances)
+}
+
+// GetSpec returns the spec the swarm was created with, or provider.ErrNotFound
+// if the swarm was created before kocho recorded it. CoreOS images given
+// without version are pinned to the version the swarm was created with.
+func (s AwsSwarm) GetSpec() (*swarmtypes.Spec, error) {
+	spec, err := parseSpecTags(s.Tags)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if spec == nil {
+		return nil, provider.ErrNotFound
+	}
+
+	if strings.HasPrefix(spec.ImageURI, coreOSImagePrefix) {
+		channel, version, err := parseCoreOSImageURI(spec.ImageURI)
+		if err == nil && version == "" {
+			for _, tag := range s.Tags {
+				if tag.Key == coreOSVersionTag {
+					spec.ImageURI = coreOSImagePrefix + channel + ":" + tag.Value
+				}
+			}
+		}
+	}
+	return spec, nil
+}
+
+// GetOutputs returns the outputs of the stack of the swarm.
+func (s AwsSwarm) GetOutputs() (map[string]string, error) {
+	stack, err := s.Provider.cloudformation.DescribeStack(s.Name)
+	if err != nil {
+		return nil, err
+	}
+	return stack.Outputs, nil
+}
+
+// toSwarmInstances converts the given AWS instances, enriching them with the
+// details of the autoscaling group, if the swarm has one, and the status of
+// their spot requests. The spot status is only informational, so failing to
+// look it up leaves it empty and prints a warning.
+func (s AwsSwarm) toSwarmInstances(awsInstances []types.Instance) ([]swarmtypes.Instance, error) {
+	autoScalingInstances := map[string]sdk.AutoScalingInstance{}
+
+	// Primary swarms consist of single machines without an autoscaling group
+	if s.Type == swarmSecondaryTemplate || s.Type == swarmStandaloneTemplate {
+		as, err := s.getAutoScaler()
+		if err != nil {
+			return nil, err
+		}
+
+		autoScalingInstances, err = s.Provider.getAutoScalingInstances(as.PhysicalId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Requests of terminated instances may have expired already
+	var spotRequestIds []string
+	for _, awsInstance := range awsInstances {
+		if awsInstance.SpotInstanceRequestId != "" && awsInstance.State != instanceStateTerminated {
+			spotRequestIds = append(spotRequestIds, awsInstance.SpotInstanceRequestId)
+		}
+	}
+	spotStatus, err := s.Provider.ec2.DescribeSpotInstanceRequestStatus(spotRequestIds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to look up spot requests of swarm %s: %v\n", s.Name, err)
+		spotStatus = map[string]string{}
+	}
+
 	var instances []swarmtypes.Instance
 	for _, awsInstance := range awsInstances {
-		instances = append(instances, swarmtypes.Instance{
+		instance := swarmtypes.Instance{
 			Id:               awsInstance.InstanceId,
 			Image:            awsInstance.ImageId,
 			Type:             awsInstance.InstanceType,
+			State:            awsInstance.State,
+			StateReason:      awsInstance.StateReason,
+			AvailabilityZone: awsInstance.AvailabilityZone,
+			LaunchTime:       awsInstance.LaunchTime,
 			PublicIPAddress:  awsInstance.PublicIPAddress,
 			PrivateIPAddress: awsInstance.PrivateIPAddress,
 			PublicDNSName:    awsInstance.PublicDNSName,
 			PrivateDNSName:   awsInstance.PrivateDNSName,
-		})
+		}
+
+		if awsInstance.Lifecycle == sdk.EC2LifecycleSpot {
+			instance.Market = swarmtypes.MarketSpot
+			instance.SpotStatus = spotStatus[awsInstance.SpotInstanceRequestId]
+		} else {
+			instance.Market = swarmtypes.MarketOnDemand
+		}
+
+		if asInstance, ok := autoScalingInstances[awsInstance.InstanceId]; ok {
+			instance.HealthStatus = asInstance.HealthStatus
+			instance.LifecycleState = asInstance.LifecycleState
+		}
+
+		instances = append(instances, instance)
 	}
 
 	return instances, nil
@@ -113,6 +236,39 @@ func (s AwsSwarm) KillInstance(i swarmtypes.Instance) error {
 	return nil
 }
 
+// IsProtected returns true if the swarm is protected against deletion.
+func (s AwsSwarm) IsProtected() bool {
+	return s.Protected
+}
+
+// SetProtected enables or disables the termination protection of the stack.
+// The termination protection decides whether the swarm is protected, the tag
+// only mirrors it. Updating the tag updates the whole stack, so it is only
+// done while the stack is in a stable state.
+func (s AwsSwarm) SetProtected(protected bool) error {
+	if err := s.Provider.cloudformation.SetTerminationProtection(s.Name, protected); err != nil {
+		return errgo.Mask(err)
+	}
+
+	stack, err := s.Provider.cloudformation.DescribeStack(s.Name)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if stack.Status != statusCreateComplete && stack.Status != statusUpdateComplete {
+		return nil
+	}
+
+	if protected {
+		err = s.Provider.cloudformation.UpdateStackTags(s.Name, map[string]string{protectedTag: "true"}, nil)
+	} else {
+		err = s.Provider.cloudformation.UpdateStackTags(s.Name, nil, []string{protectedTag})
+	}
+	if err != nil {
+		return errgo.Notef(err, "termination protection of swarm %s was updated, but its %s tag wasn't", s.Name, protectedTag)
+	}
+	return nil
+}
+
 // Destroy destroys the swarm.
 func (s AwsSwarm) Destroy() error {
 	return s.Provider.cloudformation.DeleteStack(s.Name)
@@ -134,6 +290,8 @@ func (s AwsSwarm) WaitUntil(status string) error {
 		}
 	case provider.StatusDeleted:
 		return s.waitForDeletion()
+	case provider.StatusUpdated:
+		return s.waitForUpdate()
 	default:
 		return fmt.Errorf("waiting for status '%s' is not implemented yet.", status)
 	}
@@ -159,6 +317,24 @@ func (s AwsSwarm) waitForCompletion() error {
 	return nil
 }
 
+func (s AwsSwarm) waitForUpdate() error {
+	for {
+		status, reason, err := s.GetStatus()
+		if err != nil {
+			return err
+		}
+
+		switch status {
+		case statusUpdateComplete:
+			return nil // success
+		case statusUpdateRollbackComplete, statusUpdateRollbackFailed:
+			return fmt.Errorf("update of swarm was rolled back: %s. Please check AWS Console for error details", reason)
+		}
+
+		time.Sleep(waitInterval)
+	}
+}
+
 func (s AwsSwarm) waitForDeletion() error {
 	_, _, err := s.GetStatus()
 	if err == provider.ErrNotFound {
@@ -1,6 +1,10 @@
 // Package types provides some general types used by the api clients of the provider/aws package for internal usage.
 package types
 
+import (
+	"time"
+)
+
 // Tag represents a key value pair.
 type Tag struct {
 	Key   string
@@ -15,13 +19,38 @@ type StackResource struct {
 	LogicalId  string `json:"LogicalResourceId"`
 }
 
+// ResourceDrift represents the drift of a resource in a CloudFormation stack.
+type ResourceDrift struct {
+	Type       string
+	LogicalId  string
+	PhysicalId string
+	Status     string
+}
+
+// ResourceChange represents the change of a resource in a CloudFormation change set.
+type ResourceChange struct {
+	Action      string
+	Type        string
+	LogicalId   string
+	PhysicalId  string
+	Replacement string
+}
+
 // Instance represents an instance on AWS.
 type Instance struct {
 	InstanceId       string
 	ImageId          string
 	InstanceType     string
+	State            string
+	StateReason      string
+	AvailabilityZone string
+	LaunchTime       time.Time
 	PublicIPAddress  string
 	PublicDNSName    string
 	PrivateIPAddress string
 	PrivateDNSName   string
+
+	// Lifecycle is "spot" for spot instances and empty for on-demand instances.
+	Lifecycle             string
+	SpotInstanceRequestId string
 }
@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/provider/aws/sdk"
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+// stageTemplate uploads the template file to the template bucket if it is too
+// large to be passed to CloudFormation inline. It returns the URL of the
+// uploaded template, or an empty URL if the template can be passed inline,
+// along with a function removing the uploaded template again.
+func (aws AwsProvider) stageTemplate(stackName, templateFile string, awsFlags *swarmtypes.AWSCreateFlags) (string, func(), error) {
+	noCleanup := func() {}
+
+	data, err := ioutil.ReadFile(templateFile)
+	if err != nil {
+		return "", noCleanup, errgo.Mask(err)
+	}
+	if len(data) <= sdk.MaxTemplateBodySize {
+		return "", noCleanup, nil
+	}
+	if awsFlags.TemplateBucket == "" {
+		return "", noCleanup, errgo.Newf("template of swarm %s has %d bytes, but CloudFormation only accepts %d bytes inline: configure a bucket to upload it to with --aws-template-bucket", stackName, len(data), sdk.MaxTemplateBodySize)
+	}
+
+	bucket := awsFlags.TemplateBucket
+	key := templateKey(awsFlags.TemplatePrefix, stackName, time.Now())
+	url, err := aws.s3.PutObject(bucket, key, data)
+	if err != nil {
+		return "", noCleanup, errgo.Notef(err, "failed to upload template to s3://%s/%s", bucket, key)
+	}
+
+	cleanup := func() {
+		if err := aws.s3.DeleteObject(bucket, key); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove uploaded template s3://%s/%s: %v\n", bucket, key, err)
+		}
+	}
+	return url, cleanup, nil
+}
+
+// templateKey returns the key to upload a template of the given stack to,
+// unique to the time of the upload.
+func templateKey(prefix, stackName string, now time.Time) string {
+	key := path.Join(prefix, stackName, now.UTC().Format("20060102-150405")+".json")
+	return strings.TrimPrefix(key, "/")
+}
@@ -0,0 +1,26 @@
+package aws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemplateKey(t *testing.T) {
+	now := time.Date(2016, 4, 5, 13, 14, 15, 0, time.UTC)
+
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"kocho/", "kocho/my-swarm/20160405-131415.json"},
+		{"kocho", "kocho/my-swarm/20160405-131415.json"},
+		{"/templates/kocho/", "templates/kocho/my-swarm/20160405-131415.json"},
+		{"", "my-swarm/20160405-131415.json"},
+	}
+
+	for _, test := range tests {
+		if got := templateKey(test.prefix, "my-swarm", now); got != test.want {
+			t.Errorf("templateKey(%q): expected %s, got %s", test.prefix, test.want, got)
+		}
+	}
+}
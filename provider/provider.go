@@ -10,30 +10,47 @@ import (
 
 var (
 	ErrNotFound = errors.New("not found")
+
+	// ErrConditionFailed is returned by conditional writes whose condition isn't met.
+	ErrConditionFailed = errors.New("condition failed")
 )
 
 const (
 	StatusCreated = "created"
 	StatusDeleted = "deleted"
+	StatusUpdated = "updated"
 )
 
 // ProviderSwarm represents a Swarm running in a Provider.
 type ProviderSwarm interface {
+	GetId() string
 	GetName() string
 	GetType() string
+	GetRegion() string
 	GetCreationTime() time.Time
 	GetStatus() (string, string, error)
 	GetPublicDNS() (string, error)
 	GetPrivateDNS() (string, error)
 	GetInstances() ([]swarmtypes.Instance, error)
+	GetAllInstances() ([]swarmtypes.Instance, error)
+	GetSpec() (*swarmtypes.Spec, error)
+	GetOutputs() (map[string]string, error)
 	WaitUntil(string) error
 	KillInstance(swarmtypes.Instance) error
+	IsProtected() bool
+	SetProtected(bool) error
+	Render(spec swarmtypes.Spec, cloudconfigText string) (*swarmtypes.StackDocuments, error)
+	GetDeployed() (*swarmtypes.StackDocuments, error)
+	DetectDrift() ([]swarmtypes.ResourceDrift, error)
+	CreateChangeSet(spec swarmtypes.Spec, cloudconfigText string) (*swarmtypes.ChangeSet, error)
+	ExecuteChangeSet(*swarmtypes.ChangeSet) error
+	DeleteChangeSet(*swarmtypes.ChangeSet) error
 	Destroy() error
 }
 
 // Provider represents a system that can manage Swarm.
 type Provider interface {
-	CreateSwarm(name string, flags swarmtypes.CreateFlags, cloudconfigText string) (ProviderSwarm, error)
+	CreateSwarm(name string, spec swarmtypes.Spec, cloudconfigText string) (ProviderSwarm, error)
 	GetSwarm(name string) (ProviderSwarm, error)
-	GetSwarms() ([]ProviderSwarm, error)
+	GetSwarms(includeUnmanaged bool) ([]ProviderSwarm, error)
 }
@@ -47,14 +47,27 @@ const (
 )
 
 func createCloudConfig(flags swarmtypes.CreateFlags) (string, error) {
+	return renderCloudConfig(flags, "")
+}
+
+// renderCloudConfig renders the cloud config for the given flags. Primary and standalone
+// swarms are bootstrapped with the given etcd discovery url, or a new one if empty.
+func renderCloudConfig(flags swarmtypes.CreateFlags, discoveryUrl string) (string, error) {
+	if discoveryUrl == "" && (flags.Type == "primary" || flags.Type == "standalone") {
+		var err error
+		if discoveryUrl, err = getNewDiscoveryUrl(); err != nil {
+			return "", errgo.Mask(err)
+		}
+	}
+
 	// add default tags for the primary instances
 	tags := fmt.Sprintf("role=%s,%s", flags.Type, flags.Tags)
 
 	switch flags.Type {
 	case "primary":
-		return createPrimaryCloudConfig(flags.YochuVersion, flags.FleetVersion, flags.EtcdVersion, flags.DockerVersion, flags.K8sVersion, flags.RktVersion, flags.TemplateDir, tags)
+		return createPrimaryCloudConfig(discoveryUrl, flags.YochuVersion, flags.FleetVersion, flags.EtcdVersion, flags.DockerVersion, flags.K8sVersion, flags.RktVersion, flags.TemplateDir, tags)
 	case "standalone":
-		return createStandaloneCloudConfig(flags.YochuVersion, flags.FleetVersion, flags.EtcdVersion, flags.DockerVersion, flags.K8sVersion, flags.RktVersion, flags.TemplateDir, tags)
+		return createStandaloneCloudConfig(discoveryUrl, flags.YochuVersion, flags.FleetVersion, flags.EtcdVersion, flags.DockerVersion, flags.K8sVersion, flags.RktVersion, flags.TemplateDir, tags)
 	case "secondary":
 		if flags.EtcdPeers == "" {
 			return "", errors.New("etcd peers for secondary cloud-config are missing")
@@ -71,12 +84,7 @@ func createCloudConfig(flags swarmtypes.CreateFlags) (string, error) {
 	return "", errgo.New(fmt.Sprintf("type not valid: %s", flags.Type))
 }
 
-func createPrimaryCloudConfig(yochuVersion, fleetVersion, etcdVersion, dockerVersion, k8sVersion, rktVersion, templateDir, tags string) (string, error) {
-	discoveryUrl, err := getNewDiscoveryUrl()
-	if err != nil {
-		return "", errgo.Mask(err)
-	}
-
+func createPrimaryCloudConfig(discoveryUrl, yochuVersion, fleetVersion, etcdVersion, dockerVersion, k8sVersion, rktVersion, templateDir, tags string) (string, error) {
 	cloudConfigTemplatePath := path.Join(templateDir, primaryCloudConfigTemplateName)
 
 	return parseCloudConfigTemplate(cloudConfigTemplatePath, primaryCloudConfig{
@@ -91,12 +99,7 @@ func createPrimaryCloudConfig(yochuVersion, fleetVersion, etcdVersion, dockerVer
 	})
 }
 
-func createStandaloneCloudConfig(yochuVersion, fleetVersion, etcdVersion, dockerVersion, k8sVersion, rktVersion, templateDir string, tags string) (string, error) {
-	discoveryUrl, err := getNewDiscoveryUrl()
-	if err != nil {
-		return "", errgo.Mask(err)
-	}
-
+func createStandaloneCloudConfig(discoveryUrl, yochuVersion, fleetVersion, etcdVersion, dockerVersion, k8sVersion, rktVersion, templateDir string, tags string) (string, error) {
 	cloudConfigTemplatePath := path.Join(templateDir, standaloneCloudConfigTemplateName)
 
 	return parseCloudConfigTemplate(cloudConfigTemplatePath, primaryCloudConfig{
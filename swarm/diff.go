@@ -0,0 +1,110 @@
+package swarm
+
+import (
+	"regexp"
+
+	"github.com/juju/errgo"
+
+	"github.com/giantswarm/kocho/swarm/types"
+)
+
+var discoveryURLPattern = regexp.MustCompile(`https://discovery\.etcd\.io/[0-9a-zA-Z]+`)
+
+// GetStackDocuments returns the documents the Swarm would be created with
+// from its recorded spec and the current templates, and the documents it is
+// running with. The rendered config reuses the etcd discovery url of the
+// running swarm, so only actual changes show up when comparing them.
+func (srv *Service) GetStackDocuments(s *Swarm) (*swarmtypes.StackDocuments, *swarmtypes.StackDocuments, error) {
+	spec, err := s.GetSpec()
+	if err != nil {
+		return nil, nil, errgo.Mask(err, errgo.Any)
+	}
+
+	deployed, err := s.provider.GetDeployed()
+	if err != nil {
+		return nil, nil, errgo.Mask(err)
+	}
+
+	cfg, err := renderConfig(spec.CreateFlags, deployed)
+	if err != nil {
+		return nil, nil, errgo.Mask(err)
+	}
+
+	rendered, err := s.provider.Render(*spec, cfg)
+	if err != nil {
+		return nil, nil, errgo.Mask(err)
+	}
+
+	return rendered, deployed, nil
+}
+
+// PlanUpdate creates a change set updating the Swarm to the given flags. The
+// change set is neither executed nor deleted.
+func (srv *Service) PlanUpdate(s *Swarm, flags swarmtypes.CreateFlags) (*swarmtypes.ChangeSet, error) {
+	if flags.Type != s.Type {
+		return nil, errgo.Newf("the type of swarm %s can't be changed from %s to %s", s.Name, s.Type, flags.Type)
+	}
+
+	current, err := s.GetSpec()
+	if err != nil {
+		return nil, errgo.Mask(err, errgo.Any)
+	}
+
+	deployed, err := s.provider.GetDeployed()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	if flags.Type != "secondary" && discoveryURLPattern.FindString(deployed.Config) == "" {
+		return nil, errgo.Newf("couldn't find the etcd discovery url of swarm %s in its config", s.Name)
+	}
+
+	cfg, err := renderConfig(flags, deployed)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	templateHash, err := hashTemplateDir(flags.TemplateDir)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	spec := swarmtypes.Spec{
+		CreateFlags:  flags,
+		TemplateHash: templateHash,
+		KochoVersion: srv.KochoVersion,
+		Creator:      current.Creator,
+	}
+	return s.provider.CreateChangeSet(spec, cfg)
+}
+
+// ExecuteChangeSet starts the update of the Swarm with the given change set.
+func (s *Swarm) ExecuteChangeSet(changeSet *swarmtypes.ChangeSet) error {
+	return s.provider.ExecuteChangeSet(changeSet)
+}
+
+// DeleteChangeSet deletes the given change set without executing it.
+func (s *Swarm) DeleteChangeSet(changeSet *swarmtypes.ChangeSet) error {
+	return s.provider.DeleteChangeSet(changeSet)
+}
+
+// renderConfig renders the cloud config or ignition config for the given
+// flags, reusing the etcd discovery url of the deployed swarm. A new discovery
+// url would split the etcd cluster of running swarms. Secondary swarms keep
+// the discovery url of their primary, as it isn't recorded in their spec.
+func renderConfig(flags swarmtypes.CreateFlags, deployed *swarmtypes.StackDocuments) (string, error) {
+	discoveryUrl := discoveryURLPattern.FindString(deployed.Config)
+	if flags.EtcdDiscoveryURL == "" {
+		flags.EtcdDiscoveryURL = discoveryUrl
+	}
+
+	if flags.UseIgnition {
+		return renderIgnitionConfig(flags, discoveryUrl)
+	}
+	return renderCloudConfig(flags, discoveryUrl)
+}
+
+// DetectDrift returns the resources of the Swarm that were modified outside of kocho.
+func (s *Swarm) DetectDrift() ([]swarmtypes.ResourceDrift, error) {
+	return s.provider.DetectDrift()
+}
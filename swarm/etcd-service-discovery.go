@@ -1,8 +1,10 @@
 package swarm
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/giantswarm/kocho/ssh"
 	"github.com/giantswarm/kocho/swarm/types"
@@ -39,6 +41,47 @@ func RemoveInstanceFromDiscovery(i swarmtypes.Instance) error {
 	return nil
 }
 
+// GetEtcdPeers returns the etcd peers of the Swarm, as comma separated list of
+// client URLs of its running instances.
+func (s *Swarm) GetEtcdPeers() (string, error) {
+	instances, err := s.getRunningInstances()
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+
+	etcdPeers := []string{}
+	for _, instance := range instances {
+		etcdPeers = append(etcdPeers, fmt.Sprintf("http://%v:2379", instance.PrivateIPAddress))
+	}
+	return strings.Join(etcdPeers, ","), nil
+}
+
+// GetEtcdDiscoveryURL returns the etcd discovery url the Swarm was bootstrapped with.
+func (s *Swarm) GetEtcdDiscoveryURL() (string, error) {
+	instances, err := s.getRunningInstances()
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+
+	url, err := ssh.GetEtcdDiscoveryUrl(instances[0].PublicIPAddress)
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+	return url, nil
+}
+
+func (s *Swarm) getRunningInstances() ([]swarmtypes.Instance, error) {
+	instances, err := s.GetInstances()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	if len(instances) == 0 {
+		return nil, errgo.Newf("could not find any running instances in swarm %s", s.Name)
+	}
+	return instances, nil
+}
+
 func getNewDiscoveryUrl() (string, error) {
 	resp, err := http.Get(discoveryService)
 	if err != nil {
@@ -50,14 +50,27 @@ const (
 )
 
 func createIgnitionConfig(flags swarmtypes.CreateFlags) (string, error) {
+	return renderIgnitionConfig(flags, "")
+}
+
+// renderIgnitionConfig renders the ignition config for the given flags. Primary and standalone
+// swarms are bootstrapped with the given etcd discovery url, or a new one if empty.
+func renderIgnitionConfig(flags swarmtypes.CreateFlags, discoveryUrl string) (string, error) {
+	if discoveryUrl == "" && (flags.Type == "primary" || flags.Type == "standalone") {
+		var err error
+		if discoveryUrl, err = getNewDiscoveryUrl(); err != nil {
+			return "", errgo.Mask(err)
+		}
+	}
+
 	// add default tags for the primary instances
 	tags := fmt.Sprintf("role=%s,%s", flags.Type, flags.Tags)
 
 	switch flags.Type {
 	case "primary":
-		return createPrimaryIgnitionConfig(flags.YochuVersion, flags.FleetVersion, flags.EtcdVersion, flags.DockerVersion, flags.K8sVersion, flags.RktVersion, flags.TemplateDir, tags)
+		return createPrimaryIgnitionConfig(discoveryUrl, flags.YochuVersion, flags.FleetVersion, flags.EtcdVersion, flags.DockerVersion, flags.K8sVersion, flags.RktVersion, flags.TemplateDir, tags)
 	case "standalone":
-		return createStandaloneIgnitionConfig(flags.YochuVersion, flags.FleetVersion, flags.EtcdVersion, flags.DockerVersion, flags.K8sVersion, flags.RktVersion, flags.TemplateDir, tags)
+		return createStandaloneIgnitionConfig(discoveryUrl, flags.YochuVersion, flags.FleetVersion, flags.EtcdVersion, flags.DockerVersion, flags.K8sVersion, flags.RktVersion, flags.TemplateDir, tags)
 	case "secondary":
 		if flags.EtcdPeers == "" {
 			return "", errors.New("etcd peers for secondary ignition config are missing")
@@ -74,12 +87,7 @@ func createIgnitionConfig(flags swarmtypes.CreateFlags) (string, error) {
 	return "", errgo.New(fmt.Sprintf("type not valid: %s", flags.Type))
 }
 
-func createPrimaryIgnitionConfig(yochuVersion, fleetVersion, etcdVersion, dockerVersion, k8sVersion, rktVersion, templateDir, tags string) (string, error) {
-	discoveryUrl, err := getNewDiscoveryUrl()
-	if err != nil {
-		return "", errgo.Mask(err)
-	}
-
+func createPrimaryIgnitionConfig(discoveryUrl, yochuVersion, fleetVersion, etcdVersion, dockerVersion, k8sVersion, rktVersion, templateDir, tags string) (string, error) {
 	ignitionConfigTemplatePath := path.Join(templateDir, primaryIgnitionConfigTemplateName)
 
 	ignitionTemplate, err := parseIgnitionConfigTemplate(ignitionConfigTemplatePath, primaryIgnitionConfig{
@@ -104,12 +112,7 @@ func createPrimaryIgnitionConfig(yochuVersion, fleetVersion, etcdVersion, docker
 	return string(ignitionJSON[:]), nil
 }
 
-func createStandaloneIgnitionConfig(yochuVersion, fleetVersion, etcdVersion, dockerVersion, k8sVersion, rktVersion, templateDir string, tags string) (string, error) {
-	discoveryUrl, err := getNewDiscoveryUrl()
-	if err != nil {
-		return "", errgo.Mask(err)
-	}
-
+func createStandaloneIgnitionConfig(discoveryUrl, yochuVersion, fleetVersion, etcdVersion, dockerVersion, k8sVersion, rktVersion, templateDir string, tags string) (string, error) {
 	ignitionConfigTemplatePath := path.Join(templateDir, standaloneIgnitionConfigTemplateName)
 
 	ignitionTemplate, err := parseIgnitionConfigTemplate(ignitionConfigTemplatePath, primaryIgnitionConfig{
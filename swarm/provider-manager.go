@@ -11,6 +11,9 @@ import (
 type ProviderManager struct {
 	Providers       []ProviderType
 	activeProviders []ProviderType
+
+	// regions to span with the AWS provider, the session region is used if empty
+	regions []string
 }
 
 // ProviderType describes the type of Provider.
@@ -23,11 +26,26 @@ const (
 	Conair
 )
 
-// NewManager returns a new ProviderManager.
-func NewManager() ProviderManager {
+// String returns the name of the ProviderType.
+func (t ProviderType) String() string {
+	switch t {
+	case AWS:
+		return "aws"
+	case OpenStack:
+		return "openstack"
+	case Conair:
+		return "conair"
+	default:
+		return fmt.Sprintf("unknown provider %d", int(t))
+	}
+}
+
+// NewManager returns a new ProviderManager, given the regions to span.
+func NewManager(regions []string) ProviderManager {
 	return ProviderManager{
 		Providers:       []ProviderType{AWS},
 		activeProviders: []ProviderType{AWS},
+		regions:         regions,
 	}
 }
 
@@ -35,7 +53,7 @@ func NewManager() ProviderManager {
 func (pm ProviderManager) GetByType(providerType ProviderType) (provider.Provider, error) {
 	switch providerType {
 	case AWS:
-		return aws.Init(), nil
+		return aws.Init(pm.regions...)
 	default:
 		return nil, fmt.Errorf("no provider found")
 	}
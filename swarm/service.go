@@ -1,13 +1,24 @@
 package swarm
 
 import (
+	"net"
+	"net/url"
+	"strings"
+
 	"github.com/juju/errgo"
 
+	"github.com/giantswarm/kocho/provider"
 	"github.com/giantswarm/kocho/swarm/types"
 )
 
 // Config describes the configuration of a Service.
 type Config struct {
+	// Regions to list and look up swarms in. If empty, the region of the provider session is used.
+	Regions []string
+
+	// KochoVersion and Creator are recorded in the spec of created swarms.
+	KochoVersion string
+	Creator      string
 }
 
 // Dependencies describe the dependencies of a Service.
@@ -20,7 +31,7 @@ func NewService(cfg Config, deps Dependencies) *Service {
 		Config:       cfg,
 		Dependencies: deps,
 
-		providers: NewManager(),
+		providers: NewManager(cfg.Regions),
 	}
 }
 
@@ -46,6 +57,12 @@ func (srv *Service) Create(name string, providerType ProviderType, flags swarmty
 		}
 	}
 
+	if flags.AttachTo != "" {
+		if err := srv.attach(&flags, providerType); err != nil {
+			return nil, err
+		}
+	}
+
 	var cfg string
 	if flags.UseIgnition {
 		cfg, err = createIgnitionConfig(flags)
@@ -56,7 +73,19 @@ func (srv *Service) Create(name string, providerType ProviderType, flags swarmty
 		return nil, err
 	}
 
-	swarm, err := p.CreateSwarm(name, flags, cfg)
+	templateHash, err := hashTemplateDir(flags.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := swarmtypes.Spec{
+		CreateFlags:  flags,
+		TemplateHash: templateHash,
+		KochoVersion: srv.KochoVersion,
+		Creator:      srv.Creator,
+	}
+
+	swarm, err := p.CreateSwarm(name, spec, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -64,8 +93,33 @@ func (srv *Service) Create(name string, providerType ProviderType, flags swarmty
 	return createSwarm(swarm), nil
 }
 
-// List returns all available Swarms.
-func (srv *Service) List() ([]*Swarm, error) {
+// attach resolves the etcd peers and discovery url of a secondary swarm from
+// the primary swarm given by AttachTo.
+func (srv *Service) attach(flags *swarmtypes.CreateFlags, providerType ProviderType) error {
+	if flags.Type != "secondary" {
+		return errgo.Newf("only secondary swarms can be attached to a primary swarm")
+	}
+
+	primary, err := srv.Get(flags.AttachTo, providerType)
+	if err != nil {
+		return errgo.WithCausef(err, nil, "couldn't find primary swarm: %s", flags.AttachTo)
+	}
+	if primary.Type != "primary" {
+		return errgo.Newf("swarm %s is a %s swarm, secondary swarms can only be attached to primary swarms", primary.Name, primary.Type)
+	}
+
+	if flags.EtcdPeers, err = primary.GetEtcdPeers(); err != nil {
+		return errgo.Mask(err)
+	}
+	if flags.EtcdDiscoveryURL, err = primary.GetEtcdDiscoveryURL(); err != nil {
+		return errgo.Mask(err)
+	}
+	return nil
+}
+
+// List returns all available Swarms. Unless includeUnmanaged is set, only swarms
+// created by kocho are returned.
+func (srv *Service) List(includeUnmanaged bool) ([]*Swarm, error) {
 	plist, err := srv.providers.ActiveProviders()
 	if err != nil {
 		return nil, err
@@ -73,7 +127,7 @@ func (srv *Service) List() ([]*Swarm, error) {
 
 	var swarms []*Swarm
 	for _, provider := range plist {
-		swarmList, err := provider.GetSwarms()
+		swarmList, err := provider.GetSwarms(includeUnmanaged)
 		if err != nil {
 			return nil, err
 		}
@@ -100,3 +154,120 @@ func (srv *Service) Get(name string, providerType ProviderType) (*Swarm, error)
 
 	return createSwarm(swarm), nil
 }
+
+// GetSecondaries returns the secondary Swarms attached to the given primary Swarm.
+func (srv *Service) GetSecondaries(primary *Swarm) ([]*Swarm, error) {
+	swarms, err := srv.List(false)
+	if err != nil {
+		return nil, err
+	}
+	return secondariesOf(primary, swarms)
+}
+
+// Topology describes a primary Swarm and the secondary Swarms attached to it.
+type Topology struct {
+	Primary     *Swarm
+	Secondaries []*Swarm
+}
+
+// GetTopology returns all primary Swarms with their secondaries, and the
+// secondary Swarms whose primary couldn't be found.
+func (srv *Service) GetTopology() ([]Topology, []*Swarm, error) {
+	swarms, err := srv.List(false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var topologies []Topology
+	attached := map[string]bool{}
+	for _, s := range swarms {
+		if s.Type != "primary" {
+			continue
+		}
+
+		secondaries, err := secondariesOf(s, swarms)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, secondary := range secondaries {
+			attached[secondary.Name] = true
+		}
+		topologies = append(topologies, Topology{Primary: s, Secondaries: secondaries})
+	}
+
+	var detached []*Swarm
+	for _, s := range swarms {
+		if s.Type == "secondary" && !attached[s.Name] {
+			detached = append(detached, s)
+		}
+	}
+	return topologies, detached, nil
+}
+
+// secondariesOf returns the secondary Swarms attached to the given primary
+// Swarm, as recorded by --attach-to, or detected by the etcd peers they were
+// created with.
+func secondariesOf(primary *Swarm, swarms []*Swarm) ([]*Swarm, error) {
+	var primaryHosts map[string]bool
+
+	var secondaries []*Swarm
+	for _, s := range swarms {
+		if s.Type != "secondary" || s.Region != primary.Region {
+			continue
+		}
+
+		spec, err := s.GetSpec()
+		if err == provider.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if spec.AttachTo != "" {
+			if spec.AttachTo == primary.Name {
+				secondaries = append(secondaries, s)
+			}
+			continue
+		}
+
+		// Only look up the instances of the primary, if the relationship wasn't recorded
+		if primaryHosts == nil {
+			instances, err := primary.GetInstances()
+			if err != nil {
+				return nil, err
+			}
+
+			primaryHosts = map[string]bool{}
+			for _, i := range instances {
+				primaryHosts[i.PrivateIPAddress] = true
+			}
+		}
+
+		for _, host := range etcdPeerHosts(spec.EtcdPeers) {
+			if primaryHosts[host] {
+				secondaries = append(secondaries, s)
+				break
+			}
+		}
+	}
+	return secondaries, nil
+}
+
+// etcdPeerHosts returns the hosts of the given comma separated etcd peer URLs.
+func etcdPeerHosts(peers string) []string {
+	var hosts []string
+	for _, peer := range strings.Split(peers, ",") {
+		u, err := url.Parse(strings.TrimSpace(peer))
+		if err != nil || u.Host == "" {
+			continue
+		}
+
+		host, _, err := net.SplitHostPort(u.Host)
+		if err != nil {
+			host = u.Host
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
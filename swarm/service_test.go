@@ -0,0 +1,24 @@
+package swarm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEtcdPeerHosts(t *testing.T) {
+	testCases := []struct {
+		Peers    string
+		Expected []string
+	}{
+		{"", nil},
+		{"http://10.0.0.1:2379", []string{"10.0.0.1"}},
+		{"http://10.0.0.1:2379, http://10.0.0.2:2379,https://etcd.example.com", []string{"10.0.0.1", "10.0.0.2", "etcd.example.com"}},
+		{"10.0.0.1:2379", nil},
+	}
+
+	for _, testCase := range testCases {
+		if hosts := etcdPeerHosts(testCase.Peers); !reflect.DeepEqual(hosts, testCase.Expected) {
+			t.Errorf("expected hosts %v for peers '%s', got %v", testCase.Expected, testCase.Peers, hosts)
+		}
+	}
+}
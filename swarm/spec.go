@@ -0,0 +1,46 @@
+package swarm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errgo"
+)
+
+// hashTemplateDir returns a hash over the names and contents of all files in
+// the given template directory, identifying the template pack a swarm was created with.
+func hashTemplateDir(templateDir string) (string, error) {
+	hash := sha256.New()
+
+	err := filepath.Walk(templateDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(templateDir, path)
+		if err != nil {
+			return err
+		}
+		io.WriteString(hash, rel)
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(hash, file)
+		return err
+	})
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -10,16 +10,20 @@ import (
 
 // Swarm represents a cluster of CoreOS machines.
 type Swarm struct {
+	Id       string
 	Name     string
 	Type     string
+	Region   string
 	Created  time.Time
 	provider provider.ProviderSwarm
 }
 
 func createSwarm(swarm provider.ProviderSwarm) *Swarm {
 	return &Swarm{
+		Id:       swarm.GetId(),
 		Name:     swarm.GetName(),
 		Type:     swarm.GetType(),
+		Region:   swarm.GetRegion(),
 		Created:  swarm.GetCreationTime(),
 		provider: swarm,
 	}
@@ -35,11 +39,42 @@ func (s *Swarm) GetStatus() (string, string, error) {
 	return s.provider.GetStatus()
 }
 
-// GetInstances returns all the instances of the Swarm.
+// GetInstances returns all the running instances of the Swarm.
 func (s *Swarm) GetInstances() ([]swarmtypes.Instance, error) {
 	return s.provider.GetInstances()
 }
 
+// GetAllInstances returns all the instances of the Swarm, regardless of their state.
+func (s *Swarm) GetAllInstances() ([]swarmtypes.Instance, error) {
+	return s.provider.GetAllInstances()
+}
+
+// GetSpec returns the Spec the Swarm was created with.
+func (s *Swarm) GetSpec() (*swarmtypes.Spec, error) {
+	return s.provider.GetSpec()
+}
+
+// GetExpiryTime returns the time the Swarm expires at, or the zero time if it never expires.
+func (s *Swarm) GetExpiryTime() (time.Time, error) {
+	spec, err := s.GetSpec()
+	if err == provider.ErrNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if spec.TTL == 0 {
+		return time.Time{}, nil
+	}
+	return s.Created.Add(spec.TTL), nil
+}
+
+// GetOutputs returns the outputs of the Swarm, e.g. the stack outputs on AWS.
+func (s *Swarm) GetOutputs() (map[string]string, error) {
+	return s.provider.GetOutputs()
+}
+
 // GetPublicDNS returns the public DNS address of the Swarm.
 func (s *Swarm) GetPublicDNS() (string, error) {
 	return s.provider.GetPublicDNS()
@@ -55,6 +90,16 @@ func (s *Swarm) KillInstance(i swarmtypes.Instance) error {
 	return s.provider.KillInstance(i)
 }
 
+// IsProtected returns true if the Swarm is protected against deletion.
+func (s *Swarm) IsProtected() bool {
+	return s.provider.IsProtected()
+}
+
+// SetProtected enables or disables the deletion protection of the Swarm.
+func (s *Swarm) SetProtected(protected bool) error {
+	return s.provider.SetProtected(protected)
+}
+
 // Destroy destroys the Swarm.
 func (s *Swarm) Destroy() error {
 	return s.provider.Destroy()
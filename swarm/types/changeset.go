@@ -0,0 +1,22 @@
+package swarmtypes
+
+// ChangeSet describes the changes an update of a swarm would make, before
+// they are executed.
+type ChangeSet struct {
+	Id      string
+	Changes []ResourceChange
+}
+
+// ResourceChange describes the change of a single resource of a swarm.
+type ResourceChange struct {
+	Action     string
+	Type       string
+	LogicalId  string
+	PhysicalId string
+
+	// Replacement tells whether the resource is replaced, e.g. True, False or Conditional on AWS.
+	Replacement string
+
+	// ReplacesQuorumMember is true if the change destroys a member of the etcd quorum.
+	ReplacesQuorumMember bool
+}
@@ -1,5 +1,10 @@
 package swarmtypes
 
+import (
+	"strings"
+	"time"
+)
+
 // CreateFlags describes flags for creating a swarm.
 type CreateFlags struct {
 	// Template type that should be used
@@ -11,6 +16,10 @@ type CreateFlags struct {
 	// The version tag of Yochu to be deployed
 	YochuVersion string
 
+	// Name of the primary swarm a secondary swarm is attached to. The etcd
+	// peers and discovery url are resolved from the primary swarm.
+	AttachTo string
+
 	ClusterSize      int
 	EtcdPeers        string
 	EtcdVersion      string
@@ -25,6 +34,14 @@ type CreateFlags struct {
 	// for AWS these are the EC2 types, e.g. t2.nano, m3.large etc.
 	MachineType string
 
+	// Comma separated list of machine types. If set, the provider may launch any of these types
+	// instead of only MachineType. Only supported for secondary and standalone swarms.
+	MachineTypes string
+
+	// Maximum hourly price to pay for spot instances. If empty, on-demand instances are used.
+	// Only supported for secondary and standalone swarms.
+	SpotMaxPrice string
+
 	// URI for the OS image that should be used for the nodes in the cluster. Must be understood by the provider.
 	ImageURI string
 
@@ -36,13 +53,59 @@ type CreateFlags struct {
 
 	// Use ignition as bootstrap mechanism for CoreOS
 	UseIgnition bool
+
+	// Lifetime of the swarm after its creation. Zero means the swarm never expires.
+	TTL time.Duration
+}
+
+// MachineTypeList returns the machine types given in the comma separated MachineTypes field,
+// falling back to MachineType.
+func (f CreateFlags) MachineTypeList() []string {
+	if types := splitList(f.MachineTypes); len(types) > 0 {
+		return types
+	}
+	return []string{f.MachineType}
+}
+
+// UseMixedInstances returns true if the swarm should be created with a mix of
+// machine types or spot instances.
+func (f CreateFlags) UseMixedInstances() bool {
+	return f.SpotMaxPrice != "" || f.MachineTypes != ""
 }
 
 // AWSCreateFlags describes AWS specific flags for creating a swarm.
 type AWSCreateFlags struct {
-	KeypairName      string
-	VPC              string
-	VPCCIDR          string
+	KeypairName string
+	VPC         string
+	VPCCIDR     string
+
+	// Comma separated lists of subnets and availability zones to spread the swarm across
 	Subnet           string
 	AvailabilityZone string
+
+	// S3 bucket and key prefix to upload templates to that are too large to
+	// be passed to CloudFormation inline
+	TemplateBucket string
+	TemplatePrefix string
+}
+
+// Subnets returns the subnets given in the comma separated Subnet field.
+func (f *AWSCreateFlags) Subnets() []string {
+	return splitList(f.Subnet)
+}
+
+// AvailabilityZones returns the availability zones given in the comma separated AvailabilityZone field.
+func (f *AWSCreateFlags) AvailabilityZones() []string {
+	return splitList(f.AvailabilityZone)
+}
+
+// splitList splits a comma separated list, dropping empty elements.
+func splitList(list string) []string {
+	result := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
 }
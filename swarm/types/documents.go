@@ -0,0 +1,20 @@
+package swarmtypes
+
+// StackDocuments are the documents a swarm is created from, e.g. the
+// CloudFormation template and parameters on AWS. They are used to compare a
+// running swarm with what its spec would produce.
+type StackDocuments struct {
+	Template   string
+	Parameters map[string]string
+
+	// The cloud config or ignition config the machines boot with
+	Config string
+}
+
+// ResourceDrift describes a resource of a swarm that was modified outside of kocho.
+type ResourceDrift struct {
+	Type       string
+	LogicalId  string
+	PhysicalId string
+	Status     string
+}
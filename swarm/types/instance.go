@@ -1,6 +1,9 @@
 package swarmtypes
 
 import (
+	"strings"
+	"time"
+
 	"github.com/juju/errgo"
 )
 
@@ -13,6 +16,43 @@ type Instance struct {
 	PublicDNSName    string
 	PrivateIPAddress string
 	PrivateDNSName   string
+
+	// State is the provider state of the machine, e.g. pending, running or shutting-down.
+	State            string
+	StateReason      string
+	AvailabilityZone string
+	LaunchTime       time.Time
+
+	// Market is either "spot" or "on-demand". SpotStatus is the status code of
+	// the spot request of spot machines, e.g. "fulfilled" or "marked-for-termination".
+	Market     string
+	SpotStatus string
+
+	// HealthStatus and LifecycleState are only set for machines managed by an autoscaler.
+	HealthStatus   string
+	LifecycleState string
+}
+
+const (
+	MarketSpot     = "spot"
+	MarketOnDemand = "on-demand"
+)
+
+// Healthy returns true if the machine is running and, if managed by an
+// autoscaler, healthy and in service. Spot machines about to be reclaimed
+// aren't healthy.
+func (i Instance) Healthy() bool {
+	return (i.State == "" || i.State == "running") &&
+		(i.HealthStatus == "" || i.HealthStatus == "Healthy") &&
+		(i.LifecycleState == "" || i.LifecycleState == "InService") &&
+		!i.SpotInterrupted()
+}
+
+// SpotInterrupted returns true if the provider is about to reclaim, or has
+// reclaimed, the spot machine.
+func (i Instance) SpotInterrupted() bool {
+	return i.Market == MarketSpot &&
+		(strings.HasPrefix(i.SpotStatus, "marked-for-") || strings.HasPrefix(i.SpotStatus, "instance-terminated-"))
 }
 
 // FilterInstanceById filters an instance from an existing slice by its id.
@@ -0,0 +1,16 @@
+package swarmtypes
+
+// Spec describes how a swarm was created, so it can be inspected and
+// reproduced later on.
+type Spec struct {
+	CreateFlags
+
+	// Hash over the contents of the template directory used to create the swarm
+	TemplateHash string
+
+	// Version of kocho that created the swarm
+	KochoVersion string
+
+	// Name of the user who created the swarm
+	Creator string
+}